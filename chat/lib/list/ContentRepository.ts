@@ -85,6 +85,19 @@ export interface ContentRelationship {
   created_at: string;
 }
 
+// Node returned by the content-tree action; truncated_count is how many of
+// child_count were left out by the depth or node limit
+export interface ContentTreeNode {
+  id: string;
+  type: string;
+  data: string;
+  metadata?: unknown;
+  created_at: string;
+  child_count: number;
+  truncated_count: number;
+  children: ContentTreeNode[];
+}
+
 export interface SEOMetadata {
   title?: string;
   description?: string;
@@ -1920,6 +1933,170 @@ export class ContentRepository {
     }
   }
 
+  // Content Import
+  // Imports pasted links, an RSS feed, a YouTube playlist or pre-formatted
+  // items (e.g. a Libgen selection) server-side, under a new list unless
+  // parentContentId is set. createGroup imports into a new group instead of groupId.
+  async importContent(params: {
+    groupId?: string;
+    createGroup?: boolean;
+    source:
+      | { type: "urls"; urls: string[] }
+      | { type: "rss"; feed_url: string; since?: string }
+      | { type: "youtube_playlist"; playlist_url: string; max_videos?: number }
+      | {
+          type: "items";
+          items: Array<{ type?: string; data: string; metadata?: unknown }>;
+        };
+    parentContentId?: string;
+    title?: string;
+    tags?: string[];
+  }): Promise<{
+    group_id: string;
+    parent_content_id: string;
+    content_ids: string[];
+    skipped: string[];
+    failures?: Array<{ video_id: string; title: string; reason: string }>;
+  }> {
+    const {
+      data: { user },
+    } = await supabase.auth.getUser();
+
+    if (!user) {
+      throw new Error("User not authenticated");
+    }
+
+    const response = await LambdaClient.invoke({
+      action: "content-import",
+      payload: {
+        group_id: params.groupId,
+        create_group: params.createGroup,
+        parent_content_id: params.parentContentId,
+        title: params.title,
+        tags: params.tags,
+        source: params.source,
+      },
+    });
+
+    if (!response.success) {
+      throw new Error(response.error || "Content import failed");
+    }
+
+    return response.data;
+  }
+
+  // Content Search
+  // Ranked full-text search over a group's content, with highlighted snippets
+  async searchContentFullText(params: {
+    groupId: string;
+    query: string;
+    limit?: number;
+    offset?: number;
+    includeMetadata?: boolean;
+  }): Promise<{
+    query: string;
+    results: Array<{
+      id: string;
+      type: string;
+      data: string;
+      parent_content_id?: string;
+      metadata?: unknown;
+      created_at: string;
+      rank: number;
+      snippet: string;
+    }>;
+  }> {
+    const {
+      data: { user },
+    } = await supabase.auth.getUser();
+
+    if (!user) {
+      throw new Error("User not authenticated");
+    }
+
+    const response = await LambdaClient.invoke({
+      action: "content-search",
+      payload: {
+        group_id: params.groupId,
+        query: params.query,
+        limit: params.limit,
+        offset: params.offset,
+        include_metadata: params.includeMetadata,
+      },
+    });
+
+    if (!response.success) {
+      throw new Error(response.error || "Content search failed");
+    }
+
+    return response.data;
+  }
+
+  // Content Move
+  // Re-parents content (null moves it to the top level) and keeps its
+  // content_relationships row in step, in one transaction
+  async moveContent(
+    contentId: string,
+    newParentId: string | null,
+  ): Promise<{
+    content_id: string;
+    parent_content_id?: string;
+    previous_parent_id?: string;
+  }> {
+    const response = await LambdaClient.invoke({
+      action: "content-move",
+      payload: {
+        content_id: contentId,
+        new_parent_id: newParentId,
+      },
+    });
+
+    if (!response.success) {
+      throw new Error(response.error || "Failed to move content");
+    }
+
+    return response.data;
+  }
+
+  // Content Tree
+  // Loads a group's hierarchy (or the subtree under rootId) in one request
+  // instead of fetching children level by level
+  async getContentTree(params: {
+    groupId: string;
+    rootId?: string;
+    maxDepth?: number;
+    maxNodes?: number;
+  }): Promise<{
+    group_id: string;
+    roots: ContentTreeNode[];
+    node_count: number;
+    truncated: boolean;
+  }> {
+    const {
+      data: { user },
+    } = await supabase.auth.getUser();
+
+    if (!user) {
+      throw new Error("User not authenticated");
+    }
+
+    const response = await LambdaClient.invoke({
+      action: "content-tree",
+      payload: {
+        group_id: params.groupId,
+        root_id: params.rootId,
+        max_depth: params.maxDepth,
+        max_nodes: params.maxNodes,
+      },
+    });
+
+    if (!response.success) {
+      throw new Error(response.error || "Failed to load content tree");
+    }
+
+    return response.data;
+  }
+
   // Public Content Sharing Methods
 
   // Toggle content public sharing
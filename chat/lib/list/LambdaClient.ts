@@ -1,6 +1,8 @@
 // Lambda client for calling the content API
 // Replaces Supabase Edge Function calls with Lambda endpoint
 
+import { supabase } from "@/lib/list/SupabaseClient";
+
 // Use Next.js public environment variable directly
 const LAMBDA_ENDPOINT = process.env.NEXT_PUBLIC_LAMBDA_ENDPOINT;
 
@@ -28,11 +30,20 @@ export class LambdaClient {
         throw new Error("Lambda endpoint not configured - set NEXT_PUBLIC_LAMBDA_ENDPOINT");
       }
 
+      // Content methods identify the caller from the session, not the payload
+      const {
+        data: { session },
+      } = await supabase.auth.getSession();
+      const headers: Record<string, string> = {
+        "Content-Type": "application/json",
+      };
+      if (session?.access_token) {
+        headers.Authorization = `Bearer ${session.access_token}`;
+      }
+
       const response = await fetch(LAMBDA_ENDPOINT, {
         method: "POST",
-        headers: {
-          "Content-Type": "application/json",
-        },
+        headers,
         body: JSON.stringify(request),
       });
 
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// openDatabase connects to Postgres using the DATABASE_URL environment variable.
+// The handler process is short-lived, so a small pool is enough.
+func openDatabase() (*sql.DB, error) {
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		return nil, withErrorCode(errCodeInternal, fmt.Errorf("DATABASE_URL is not configured"))
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(2)
+	db.SetConnMaxLifetime(time.Minute)
+
+	return db, nil
+}
+
+// handleContentSearch runs a ranked Postgres full-text search over content in a group
+func handleContentSearch(params json.RawMessage) (*ContentSearchResponse, error) {
+	var req ContentSearchRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, invalidRequest("invalid content search request: %w", err)
+	}
+
+	if !uuidPattern.MatchString(req.GroupID) {
+		return nil, invalidRequest("group_id field is required and must be a UUID")
+	}
+
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		return nil, invalidRequest("query field is required")
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = defaultSearchLimit
+	}
+	if req.Limit > maxSearchLimit {
+		req.Limit = maxSearchLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	fmt.Fprintf(os.Stderr, "DEBUG: Searching group %s for %q (limit %d, offset %d)\n", req.GroupID, req.Query, req.Limit, req.Offset)
+
+	rows, err := db.Query(contentSearchSQL(req.IncludeMetadata), req.GroupID, req.Query, req.Limit, req.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("search query failed: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]ContentSearchResult, 0, req.Limit)
+	for rows.Next() {
+		var result ContentSearchResult
+		var parentID sql.NullString
+		var metadata []byte
+		var createdAt time.Time
+		if err := rows.Scan(&result.ID, &result.Type, &result.Data, &parentID, &metadata, &createdAt, &result.Rank, &result.Snippet); err != nil {
+			return nil, fmt.Errorf("failed to read search result: %w", err)
+		}
+		if parentID.Valid {
+			result.ParentContentID = parentID.String
+		}
+		if len(metadata) > 0 {
+			result.Metadata = json.RawMessage(metadata)
+		}
+		result.CreatedAt = createdAt.Format("2006-01-02T15:04:05Z07:00")
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read search results: %w", err)
+	}
+
+	return &ContentSearchResponse{
+		Query:   req.Query,
+		Results: results,
+	}, nil
+}
+
+// contentSearchSQL builds the search query. Matching on data uses the indexed
+// search_vector column; metadata matching is computed on the fly and so is opt-in.
+func contentSearchSQL(includeMetadata bool) string {
+	document := "c.search_vector"
+	if includeMetadata {
+		document = "(coalesce(c.search_vector, ''::tsvector) || to_tsvector('english', coalesce(c.metadata::text, '')))"
+	}
+
+	return fmt.Sprintf(`
+		SELECT c.id, c.type, c.data, c.parent_content_id, c.metadata, c.created_at,
+			ts_rank(%[1]s, q) AS rank,
+			ts_headline('english', c.data, q, 'MaxFragments=2, MaxWords=20, MinWords=5, StartSel=<b>, StopSel=</b>') AS snippet
+		FROM content c, websearch_to_tsquery('english', $2) q
+		WHERE c.group_id = $1 AND %[1]s @@ q
+		ORDER BY rank DESC, c.updated_at DESC
+		LIMIT $3 OFFSET $4`, document)
+}
+
+// handleContentMove re-parents a content item, or moves it to the top level when
+// new_parent_id is null. Moving an item under itself or one of its descendants is
+// rejected so the hierarchy stays a tree.
+func handleContentMove(params json.RawMessage) (*ContentMoveResponse, error) {
+	var req ContentMoveRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, invalidRequest("invalid content move request: %w", err)
+	}
+
+	if !uuidPattern.MatchString(req.ContentID) {
+		return nil, invalidRequest("content_id field is required and must be a UUID")
+	}
+
+	if req.NewParentID != nil {
+		if !uuidPattern.MatchString(*req.NewParentID) {
+			return nil, invalidRequest("new_parent_id must be a UUID or null")
+		}
+		if strings.EqualFold(*req.NewParentID, req.ContentID) {
+			return nil, invalidRequest("cannot move content under itself")
+		}
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var groupID string
+	var previousParentID sql.NullString
+	err = tx.QueryRow(`SELECT group_id, parent_content_id FROM content WHERE id = $1 FOR UPDATE`, req.ContentID).Scan(&groupID, &previousParentID)
+	if err == sql.ErrNoRows {
+		return nil, withErrorCode(errCodeNotFound, fmt.Errorf("content %s not found", req.ContentID))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load content: %w", err)
+	}
+
+	if req.NewParentID != nil {
+		var parentGroupID string
+		err = tx.QueryRow(`SELECT group_id FROM content WHERE id = $1 FOR UPDATE`, *req.NewParentID).Scan(&parentGroupID)
+		if err == sql.ErrNoRows {
+			return nil, withErrorCode(errCodeNotFound, fmt.Errorf("parent content %s not found", *req.NewParentID))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load parent content: %w", err)
+		}
+		if parentGroupID != groupID {
+			return nil, invalidRequest("cannot move content to a parent in a different group")
+		}
+
+		// Walk up from the new parent; finding the moved item means it would become its own ancestor
+		var createsCycle bool
+		if err := tx.QueryRow(contentAncestrySQL, *req.NewParentID, req.ContentID).Scan(&createsCycle); err != nil {
+			return nil, fmt.Errorf("failed to check content ancestry: %w", err)
+		}
+		if createsCycle {
+			return nil, invalidRequest("cannot move content under one of its own descendants")
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "DEBUG: Moving content %s under %v\n", req.ContentID, formatParentID(req.NewParentID))
+
+	if _, err := tx.Exec(`UPDATE content SET parent_content_id = $2, updated_at = NOW() WHERE id = $1`, req.ContentID, req.NewParentID); err != nil {
+		return nil, fmt.Errorf("failed to move content: %w", err)
+	}
+
+	var oldParentID *string
+	if previousParentID.Valid {
+		oldParentID = &previousParentID.String
+	}
+	if err := moveContentRelationship(tx, req.ContentID, oldParentID, req.NewParentID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit move: %w", err)
+	}
+
+	resp := &ContentMoveResponse{ContentID: req.ContentID}
+	if req.NewParentID != nil {
+		resp.ParentContentID = *req.NewParentID
+	}
+	if previousParentID.Valid {
+		resp.PreviousParentID = previousParentID.String
+	}
+	return resp, nil
+}
+
+// contentAncestrySQL reports whether $2 is $1 or one of its ancestors. UNION
+// (not UNION ALL) stops the walk if existing data already contains a cycle.
+const contentAncestrySQL = `
+	WITH RECURSIVE ancestors AS (
+		SELECT id, parent_content_id FROM content WHERE id = $1
+		UNION
+		SELECT c.id, c.parent_content_id FROM content c JOIN ancestors a ON c.id = a.parent_content_id
+	)
+	SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`
+
+// sqlExecer is the part of *sql.Tx used to rewrite relationships
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// moveContentRelationship replaces the content_relationships row linking the
+// content to its old parent. The app lists the hierarchy from that table and
+// the insert trigger never updates it, so a move has to rewrite it here.
+// A nil parent is the top level (from_content_id IS NULL).
+func moveContentRelationship(tx sqlExecer, contentID string, oldParentID, newParentID *string) error {
+	if _, err := tx.Exec(`DELETE FROM content_relationships WHERE from_content_id IS NOT DISTINCT FROM $1 AND to_content_id = $2`, oldParentID, contentID); err != nil {
+		return fmt.Errorf("failed to remove old content relationship: %w", err)
+	}
+
+	// The unique index is NULLS NOT DISTINCT, so this also matches top-level rows
+	if _, err := tx.Exec(`
+		INSERT INTO content_relationships (from_content_id, to_content_id, display_order)
+		VALUES ($1, $2, 0)
+		ON CONFLICT (from_content_id, to_content_id) DO NOTHING`, newParentID, contentID); err != nil {
+		return fmt.Errorf("failed to create content relationship: %w", err)
+	}
+	return nil
+}
+
+func formatParentID(parentID *string) string {
+	if parentID == nil {
+		return "top level"
+	}
+	return *parentID
+}
+
+const (
+	defaultTreeMaxDepth = 5
+	maxTreeMaxDepth     = 20
+	defaultTreeMaxNodes = 500
+	maxTreeMaxNodes     = 5000
+)
+
+// contentTreeRow is a single content row from contentTreeSQL
+type contentTreeRow struct {
+	node     ContentTreeNode
+	parentID string
+	depth    int
+	created  time.Time
+}
+
+// handleContentTree returns a group's content hierarchy, or the subtree under
+// root_id, as nested nodes bounded by max_depth and max_nodes
+func handleContentTree(params json.RawMessage) (*ContentTreeResponse, error) {
+	var req ContentTreeRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, invalidRequest("invalid content tree request: %w", err)
+	}
+
+	if !uuidPattern.MatchString(req.GroupID) {
+		return nil, invalidRequest("group_id field is required and must be a UUID")
+	}
+
+	var rootID interface{}
+	if req.RootID != "" {
+		if !uuidPattern.MatchString(req.RootID) {
+			return nil, invalidRequest("root_id must be a UUID")
+		}
+		rootID = req.RootID
+	}
+
+	if req.MaxDepth <= 0 {
+		req.MaxDepth = defaultTreeMaxDepth
+	}
+	if req.MaxDepth > maxTreeMaxDepth {
+		req.MaxDepth = maxTreeMaxDepth
+	}
+	if req.MaxNodes <= 0 {
+		req.MaxNodes = defaultTreeMaxNodes
+	}
+	if req.MaxNodes > maxTreeMaxNodes {
+		req.MaxNodes = maxTreeMaxNodes
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	fmt.Fprintf(os.Stderr, "DEBUG: Loading content tree for group %s (max depth %d, max nodes %d)\n", req.GroupID, req.MaxDepth, req.MaxNodes)
+
+	// Fetch one row past the limit so a tree of exactly max_nodes isn't reported as truncated
+	rows, err := db.Query(contentTreeSQL, req.GroupID, rootID, req.MaxDepth, req.MaxNodes+1)
+	if err != nil {
+		return nil, fmt.Errorf("tree query failed: %w", err)
+	}
+	defer rows.Close()
+
+	treeRows := make([]contentTreeRow, 0, req.MaxNodes+1)
+	for rows.Next() {
+		var row contentTreeRow
+		var parentID sql.NullString
+		var metadata []byte
+		if err := rows.Scan(&row.node.ID, &row.node.Type, &row.node.Data, &parentID, &metadata, &row.created, &row.depth, &row.node.ChildCount); err != nil {
+			return nil, fmt.Errorf("failed to read tree row: %w", err)
+		}
+		if parentID.Valid {
+			row.parentID = parentID.String
+		}
+		if len(metadata) > 0 {
+			row.node.Metadata = json.RawMessage(metadata)
+		}
+		row.node.CreatedAt = row.created.Format("2006-01-02T15:04:05Z07:00")
+		treeRows = append(treeRows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tree rows: %w", err)
+	}
+
+	if req.RootID != "" && len(treeRows) == 0 {
+		return nil, withErrorCode(errCodeNotFound, fmt.Errorf("content %s not found in group %s", req.RootID, req.GroupID))
+	}
+
+	treeRows, overLimit := limitTreeRows(treeRows, req.MaxNodes)
+	roots, truncated := buildContentTree(treeRows)
+
+	return &ContentTreeResponse{
+		GroupID:   req.GroupID,
+		Roots:     roots,
+		NodeCount: len(treeRows),
+		Truncated: truncated || overLimit,
+	}, nil
+}
+
+// limitTreeRows trims rows fetched with one extra row back to maxNodes and
+// reports whether the limit cut anything off. The query returns levels in
+// order, so the dropped row is from the deepest level loaded.
+func limitTreeRows(rows []contentTreeRow, maxNodes int) ([]contentTreeRow, bool) {
+	if len(rows) <= maxNodes {
+		return rows, false
+	}
+	return rows[:maxNodes], true
+}
+
+// buildContentTree nests rows under their parents, ordering siblings by creation
+// time, and sets each node's TruncatedCount to the children left out by the depth
+// or node limit. It reports whether anything was left out.
+func buildContentTree(rows []contentTreeRow) ([]*ContentTreeNode, bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].depth != rows[j].depth {
+			return rows[i].depth < rows[j].depth
+		}
+		if !rows[i].created.Equal(rows[j].created) {
+			return rows[i].created.Before(rows[j].created)
+		}
+		return rows[i].node.ID < rows[j].node.ID
+	})
+
+	nodes := make(map[string]*ContentTreeNode, len(rows))
+	roots := []*ContentTreeNode{}
+	for i := range rows {
+		node := &rows[i].node
+		node.Children = []*ContentTreeNode{}
+
+		// Rows are ordered by depth, so a parent is always seen before its children
+		if rows[i].depth == 0 {
+			roots = append(roots, node)
+		} else if parent, ok := nodes[rows[i].parentID]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			continue
+		}
+		nodes[node.ID] = node
+	}
+
+	truncated := false
+	for _, node := range nodes {
+		node.TruncatedCount = node.ChildCount - len(node.Children)
+		if node.TruncatedCount > 0 {
+			truncated = true
+		}
+	}
+
+	return roots, truncated
+}
+
+// contentTreeSQL walks the hierarchy from the group's top-level items, or from $2
+// when set, down to depth $3. Postgres evaluates the recursive CTE one level at a
+// time, so the unordered LIMIT keeps whole upper levels and stops the walk early
+// instead of loading the entire group.
+const contentTreeSQL = `
+	WITH RECURSIVE tree AS (
+		SELECT c.id, c.type, c.data, c.parent_content_id, c.metadata, c.created_at, 0 AS depth
+		FROM content c
+		WHERE c.group_id = $1
+			AND CASE WHEN $2::uuid IS NULL THEN c.parent_content_id IS NULL ELSE c.id = $2::uuid END
+		UNION ALL
+		SELECT c.id, c.type, c.data, c.parent_content_id, c.metadata, c.created_at, t.depth + 1
+		FROM content c JOIN tree t ON c.parent_content_id = t.id
+		WHERE c.group_id = $1 AND t.depth < $3
+	)
+	SELECT t.id, t.type, t.data, t.parent_content_id, t.metadata, t.created_at, t.depth,
+		(SELECT count(*) FROM content k WHERE k.parent_content_id = t.id) AS child_count
+	FROM (SELECT * FROM tree LIMIT $4) t`
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxImportItems = 1000
+
+	// Imported URLs are enriched with link metadata a few at a time, within a
+	// budget that leaves room for the inserts under the caller's 60s timeout.
+	// Links not reached in time keep just their URL.
+	importLinkTimeout = 5 * time.Second
+	importLinkWorkers = 8
+	importLinkBudget  = 20 * time.Second
+)
+
+// Default titles for the list created to hold imported content
+var defaultImportTitles = map[string]string{
+	"urls":  "Imported links",
+	"rss":   "Imported feed",
+	"items": "Imported items",
+}
+
+// importBatch is the content collected from an import source
+type importBatch struct {
+	Items   []ContentImportItem
+	Skipped []string // Inputs that couldn't be imported
+	Title   string   // Source's own title (e.g. the feed's), used when the request has none
+	Tags    []string // Tags implied by the source, applied alongside the requested ones
+}
+
+// handleContentImport inserts content from a source descriptor in one transaction:
+// optionally a new group, a parent list (or an existing parent), one child per
+// imported item, and tags applied to the parent and every child
+func handleContentImport(params json.RawMessage) (*ContentImportResponse, error) {
+	var req ContentImportRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, invalidRequest("invalid content import request: %w", err)
+	}
+
+	if req.CreateGroup {
+		if req.GroupID != "" || req.ParentContentID != "" {
+			return nil, invalidRequest("create_group can't be combined with group_id or parent_content_id")
+		}
+	} else if !uuidPattern.MatchString(req.GroupID) {
+		return nil, invalidRequest("group_id field is required and must be a UUID")
+	}
+	if !uuidPattern.MatchString(req.UserID) {
+		return nil, invalidRequest("user_id field is required and must be a UUID")
+	}
+	if req.ParentContentID != "" && !uuidPattern.MatchString(req.ParentContentID) {
+		return nil, invalidRequest("parent_content_id must be a UUID")
+	}
+
+	batch, err := collectImportItems(req.Source, newFetchClient(importLinkTimeout, checkFetchAddress))
+	if err != nil {
+		return nil, err
+	}
+	if len(batch.Items) == 0 {
+		return nil, invalidRequest("source contains no importable items")
+	}
+	if len(batch.Items) > maxImportItems {
+		return nil, invalidRequest("source contains %d items; at most %d can be imported at once", len(batch.Items), maxImportItems)
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := importContent(tx, req, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	return result, nil
+}
+
+// importContent writes a validated request's collected items within the provided
+// transaction. Caller controls transaction lifecycle (commit or rollback).
+func importContent(tx *sql.Tx, req ContentImportRequest, batch *importBatch) (*ContentImportResponse, error) {
+	tags := normalizeImportTags(append(req.Tags, batch.Tags...))
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = batch.Title
+	}
+	if title == "" {
+		title = defaultImportTitles[req.Source.Type]
+	}
+
+	var err error
+	groupID := req.GroupID
+	if req.CreateGroup {
+		groupID, err = createImportGroup(tx, title, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Inserts bypass row-level security, so check membership explicitly
+		var isMember bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM group_memberships WHERE group_id = $1 AND user_id = $2)`, groupID, req.UserID).Scan(&isMember); err != nil {
+			return nil, fmt.Errorf("failed to check group membership: %w", err)
+		}
+		if !isMember {
+			return nil, invalidRequest("user %s is not a member of group %s", req.UserID, groupID)
+		}
+	}
+
+	parentID := req.ParentContentID
+	if parentID != "" {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM content WHERE id = $1 AND group_id = $2)`, parentID, groupID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to load parent content: %w", err)
+		}
+		if !exists {
+			return nil, withErrorCode(errCodeNotFound, fmt.Errorf("parent content %s not found in group %s", parentID, groupID))
+		}
+	} else {
+		parentID, err = insertImportContent(tx, groupID, req.UserID, nil, ContentImportItem{Type: "list", Data: title})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create parent list: %w", err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "DEBUG: Importing %d %s items under %s\n", len(batch.Items), req.Source.Type, parentID)
+
+	contentIDs := make([]string, 0, len(batch.Items))
+	for _, item := range batch.Items {
+		id, err := insertImportContent(tx, groupID, req.UserID, &parentID, item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert content: %w", err)
+		}
+		contentIDs = append(contentIDs, id)
+	}
+
+	if len(tags) > 0 {
+		tagged := append([]string{parentID}, contentIDs...)
+		if err := applyImportTags(tx, req.UserID, tags, tagged); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ContentImportResponse{
+		GroupID:         groupID,
+		ParentContentID: parentID,
+		ContentIDs:      contentIDs,
+		Skipped:         batch.Skipped,
+	}, nil
+}
+
+// createImportGroup creates a group for an import and makes the user its admin
+func createImportGroup(tx *sql.Tx, name, userID string) (string, error) {
+	var groupID string
+	if err := tx.QueryRow(`INSERT INTO groups (name, created_by) VALUES ($1, $2) RETURNING id`, name, userID).Scan(&groupID); err != nil {
+		return "", fmt.Errorf("failed to create group: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO group_memberships (user_id, group_id, role) VALUES ($1, $2, 'admin')
+		ON CONFLICT (user_id, group_id) DO NOTHING`, userID, groupID); err != nil {
+		return "", fmt.Errorf("failed to add group membership: %w", err)
+	}
+	return groupID, nil
+}
+
+// collectImportItems turns a source descriptor into content rows to insert,
+// along with the inputs that were skipped. client fetches feeds and link metadata.
+func collectImportItems(source ContentImportSource, client *http.Client) (*importBatch, error) {
+	skipped := []string{}
+
+	switch source.Type {
+	case "urls":
+		items := []ContentImportItem{}
+		seen := make(map[string]bool)
+		for _, raw := range source.URLs {
+			link := strings.TrimSpace(raw)
+			if link == "" {
+				continue
+			}
+			parsed, err := url.Parse(link)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				skipped = append(skipped, link)
+				continue
+			}
+			if seen[link] {
+				continue
+			}
+			seen[link] = true
+
+			metadata, _ := json.Marshal(map[string]string{"url": link})
+			items = append(items, ContentImportItem{Type: "text", Data: link, Metadata: metadata})
+		}
+		enrichImportLinks(client, items)
+		return &importBatch{Items: items, Skipped: skipped}, nil
+
+	case "rss":
+		feed, err := fetchFeed(client, RSSFetchRequest{URL: strings.TrimSpace(source.FeedURL), Since: source.Since})
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]ContentImportItem, 0, len(feed.Entries))
+		for i, entry := range feed.Entries {
+			if entry.URL == "" {
+				skipped = append(skipped, fmt.Sprintf("entry %d: no link", i))
+				continue
+			}
+			metadata, _ := json.Marshal(map[string]string{
+				"title":      entry.Title,
+				"url":        entry.URL,
+				"author":     entry.Author,
+				"published":  entry.Published,
+				"summary":    entry.Summary,
+				"feed_url":   feed.URL,
+				"feed_title": feed.Title,
+			})
+			items = append(items, ContentImportItem{Type: "text", Data: entry.URL, Metadata: metadata})
+		}
+		return &importBatch{Items: items, Skipped: skipped, Title: strings.TrimSpace(feed.Title), Tags: []string{"rss"}}, nil
+
+	case "items":
+		items := make([]ContentImportItem, 0, len(source.Items))
+		for i, item := range source.Items {
+			if strings.TrimSpace(item.Data) == "" {
+				skipped = append(skipped, fmt.Sprintf("item %d: empty data", i))
+				continue
+			}
+			if item.Type == "" {
+				item.Type = "text"
+			}
+			if len(item.Metadata) > 0 && !json.Valid(item.Metadata) {
+				skipped = append(skipped, fmt.Sprintf("item %d: invalid metadata", i))
+				continue
+			}
+			items = append(items, item)
+		}
+		return &importBatch{Items: items, Skipped: skipped}, nil
+
+	default:
+		return nil, invalidRequest("unknown source type %q: use urls, rss or items", source.Type)
+	}
+}
+
+// enrichImportLinks replaces each URL item's metadata with the page's link
+// metadata, in the shape SEO extraction stores. Failed links are left as they are.
+func enrichImportLinks(client *http.Client, items []ContentImportItem) {
+	ctx, cancel := context.WithTimeout(context.Background(), importLinkBudget)
+	defer cancel()
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < importLinkWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				link, err := fetchLinkMetadata(ctx, client, items[i].Data)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "WARNING: No link metadata for %s: %v\n", items[i].Data, err)
+					continue
+				}
+				items[i].Metadata = linkImportMetadata(items[i].Data, link)
+			}
+		}()
+	}
+
+feed:
+	for i := range items {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// linkImportMetadata builds a URL item's metadata from its link metadata,
+// leaving out empty fields
+func linkImportMetadata(rawURL string, link *LinkMetadataResponse) json.RawMessage {
+	fields := map[string]string{
+		"url":         rawURL,
+		"title":       link.Title,
+		"description": link.Description,
+		"image":       link.Image,
+		"favicon":     link.Favicon,
+		"siteName":    link.SiteName,
+	}
+	if parsed, err := url.Parse(link.URL); err == nil {
+		fields["domain"] = parsed.Hostname()
+	}
+
+	metadata := make(map[string]string, len(fields))
+	for key, value := range fields {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+
+	encoded, _ := json.Marshal(metadata)
+	return encoded
+}
+
+// insertImportContent inserts one content row and returns its ID
+func insertImportContent(tx *sql.Tx, groupID, userID string, parentID *string, item ContentImportItem) (string, error) {
+	var metadata interface{}
+	if len(item.Metadata) > 0 {
+		metadata = string(item.Metadata)
+	}
+
+	var id string
+	err := tx.QueryRow(`
+		INSERT INTO content (type, data, metadata, group_id, user_id, parent_content_id)
+		VALUES ($1, $2, $3::jsonb, $4, $5, $6)
+		RETURNING id`, item.Type, item.Data, metadata, groupID, userID, parentID).Scan(&id)
+	return id, err
+}
+
+// applyImportTags finds or creates each of the user's tags and attaches them to the content
+func applyImportTags(tx *sql.Tx, userID string, tags []string, contentIDs []string) error {
+	for _, name := range tags {
+		// DO UPDATE (rather than DO NOTHING) so RETURNING yields the existing tag's ID
+		var tagID string
+		err := tx.QueryRow(`
+			INSERT INTO tags (name, user_id) VALUES ($1, $2)
+			ON CONFLICT (name, user_id) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id`, name, userID).Scan(&tagID)
+		if err != nil {
+			return fmt.Errorf("failed to create tag %q: %w", name, err)
+		}
+
+		for _, contentID := range contentIDs {
+			if _, err := tx.Exec(`INSERT INTO content_tags (content_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, contentID, tagID); err != nil {
+				return fmt.Errorf("failed to tag content with %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeImportTags trims and de-duplicates tag names
+func normalizeImportTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		name := strings.TrimSpace(tag)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
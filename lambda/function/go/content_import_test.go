@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestContentImportValidation tests request validation before any database access
+func TestContentImportValidation(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+
+	groupID := "6f1c2d3e-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+	userID := "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+
+	testCases := []struct {
+		request      ContentImportRequest
+		expectedErr  string
+		expectedCode string
+		desc         string
+	}{
+		{
+			request:      ContentImportRequest{UserID: userID, Source: ContentImportSource{Type: "urls", URLs: []string{"https://example.com"}}},
+			expectedErr:  "group_id",
+			expectedCode: errCodeInvalidRequest,
+			desc:         "missing group_id",
+		},
+		{
+			request:      ContentImportRequest{GroupID: groupID, Source: ContentImportSource{Type: "urls", URLs: []string{"https://example.com"}}},
+			expectedErr:  "user_id",
+			expectedCode: errCodeInvalidRequest,
+			desc:         "missing user_id",
+		},
+		{
+			request:      ContentImportRequest{GroupID: groupID, UserID: userID, CreateGroup: true, Source: ContentImportSource{Type: "urls", URLs: []string{"https://example.com"}}},
+			expectedErr:  "create_group",
+			expectedCode: errCodeInvalidRequest,
+			desc:         "create_group with an existing group",
+		},
+		{
+			request:      ContentImportRequest{GroupID: groupID, UserID: userID, Source: ContentImportSource{Type: "pocket"}},
+			expectedErr:  "unknown source type",
+			expectedCode: errCodeInvalidRequest,
+			desc:         "unknown source type",
+		},
+		{
+			request:      ContentImportRequest{GroupID: groupID, UserID: userID, Source: ContentImportSource{Type: "urls", URLs: []string{"not a url"}}},
+			expectedErr:  "no importable items",
+			expectedCode: errCodeInvalidRequest,
+			desc:         "no valid URLs",
+		},
+		{
+			request:      ContentImportRequest{GroupID: groupID, UserID: userID, Source: ContentImportSource{Type: "urls", URLs: []string{"https://example.com"}}},
+			expectedErr:  "DATABASE_URL",
+			expectedCode: errCodeInternal,
+			desc:         "valid request without database configured",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			reqJSON, _ := json.Marshal(tc.request)
+			_, err := handleContentImport(json.RawMessage(reqJSON))
+			if err == nil {
+				t.Fatalf("Expected error for %s, but got none", tc.desc)
+			}
+			if !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Fatalf("Expected error mentioning %q, got: %v", tc.expectedErr, err)
+			}
+			if code := errorCode(err); code != tc.expectedCode {
+				t.Errorf("Expected code %q, got %q", tc.expectedCode, code)
+			}
+
+			t.Logf("✓ Correctly returned error: %v", err)
+		})
+	}
+}
+
+// TestCollectImportItems tests how url and item sources become content rows
+func TestCollectImportItems(t *testing.T) {
+	testCases := []struct {
+		source          ContentImportSource
+		expectedData    []string
+		expectedSkipped []string
+		desc            string
+	}{
+		{
+			source: ContentImportSource{Type: "urls", URLs: []string{
+				" https://example.com/a ", "", "ftp://example.com/file", "https://example.com/a", "http://example.org",
+			}},
+			expectedData:    []string{"https://example.com/a", "http://example.org"},
+			expectedSkipped: []string{"ftp://example.com/file"},
+			desc:            "urls are trimmed, validated and de-duplicated",
+		},
+		{
+			source: ContentImportSource{Type: "items", Items: []ContentImportItem{
+				{Data: "📚 Designing Interfaces", Metadata: json.RawMessage(`{"libgen":{"md5":"abc"}}`)},
+				{Data: "   "},
+				{Type: "book", Data: "Bad metadata", Metadata: json.RawMessage(`{not json`)},
+			}},
+			expectedData:    []string{"📚 Designing Interfaces"},
+			expectedSkipped: []string{"item 1: empty data", "item 2: invalid metadata"},
+			desc:            "items keep their metadata and skip invalid entries",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			batch, err := collectImportItems(tc.source, newOfflineFetchClient())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			items, skipped := batch.Items, batch.Skipped
+
+			if len(items) != len(tc.expectedData) {
+				t.Fatalf("Expected %d items, got %d: %+v", len(tc.expectedData), len(items), items)
+			}
+			for i, item := range items {
+				if item.Data != tc.expectedData[i] {
+					t.Errorf("Item %d: expected data %q, got %q", i, tc.expectedData[i], item.Data)
+				}
+				if item.Type != "text" {
+					t.Errorf("Item %d: expected default type text, got %q", i, item.Type)
+				}
+				if !json.Valid(item.Metadata) {
+					t.Errorf("Item %d: invalid metadata %s", i, item.Metadata)
+				}
+			}
+
+			if strings.Join(skipped, "|") != strings.Join(tc.expectedSkipped, "|") {
+				t.Errorf("Expected skipped %v, got %v", tc.expectedSkipped, skipped)
+			}
+
+			t.Logf("✓ Validated: %s", tc.desc)
+		})
+	}
+}
+
+// TestCollectRSSImportItems tests that feed entries become rss-tagged text items titled after the feed
+func TestCollectRSSImportItems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(rssFixture))
+	}))
+	defer server.Close()
+
+	batch, err := collectImportItems(ContentImportSource{Type: "rss", FeedURL: server.URL, Since: "2025-01-01"}, newTestFetchClient())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if batch.Title != "Example Blog" {
+		t.Errorf("Expected feed title as batch title, got %q", batch.Title)
+	}
+	if strings.Join(batch.Tags, ",") != "rss" {
+		t.Errorf("Expected rss tag, got %v", batch.Tags)
+	}
+
+	expectedURLs := []string{"https://blog.example.com/newest", "https://blog.example.com/undated"}
+	if len(batch.Items) != len(expectedURLs) {
+		t.Fatalf("Expected %d items, got %d: %+v", len(expectedURLs), len(batch.Items), batch.Items)
+	}
+	for i, item := range batch.Items {
+		if item.Type != "text" || item.Data != expectedURLs[i] {
+			t.Errorf("Item %d: expected text %q, got %s %q", i, expectedURLs[i], item.Type, item.Data)
+		}
+
+		var metadata map[string]string
+		if err := json.Unmarshal(item.Metadata, &metadata); err != nil {
+			t.Fatalf("Item %d: invalid metadata %s", i, item.Metadata)
+		}
+		if metadata["feed_title"] != "Example Blog" || metadata["feed_url"] != server.URL {
+			t.Errorf("Item %d: missing feed metadata: %v", i, metadata)
+		}
+	}
+
+	t.Logf("✓ Imported %d entries from %s", len(batch.Items), batch.Title)
+}
+
+// TestEnrichImportLinks tests that imported URLs get their page's link metadata
+func TestEnrichImportLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><meta property="og:title" content="Imported Page"><meta name="description" content="About the page"></head></html>`))
+	}))
+	defer server.Close()
+
+	source := ContentImportSource{Type: "urls", URLs: []string{server.URL + "/page", server.URL + "/missing"}}
+	batch, err := collectImportItems(source, newTestFetchClient())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(batch.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(batch.Items))
+	}
+
+	var enriched, plain map[string]string
+	json.Unmarshal(batch.Items[0].Metadata, &enriched)
+	json.Unmarshal(batch.Items[1].Metadata, &plain)
+
+	if enriched["title"] != "Imported Page" || enriched["description"] != "About the page" || enriched["url"] != server.URL+"/page" {
+		t.Errorf("Expected link metadata on the page item, got %v", enriched)
+	}
+	if len(plain) != 1 || plain["url"] != server.URL+"/missing" {
+		t.Errorf("Expected only the URL on the failed item, got %v", plain)
+	}
+
+	t.Logf("✓ Enriched %s", enriched["title"])
+}
+
+// TestNormalizeImportTags tests tag trimming and de-duplication
+func TestNormalizeImportTags(t *testing.T) {
+	tags := normalizeImportTags([]string{" youtube ", "", "youtube", "Music"})
+	if strings.Join(tags, ",") != "youtube,Music" {
+		t.Errorf("Expected [youtube Music], got %v", tags)
+	}
+
+	t.Log("✓ Tags trimmed and de-duplicated")
+}
+
+// TestContentImportIntegration imports into a new group against a live database
+// (VALIDATION MODE - ROLLBACK). Set DATABASE_URL and IMPORT_USER_ID to run it.
+func TestContentImportIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	userID := os.Getenv("IMPORT_USER_ID")
+	if os.Getenv("DATABASE_URL") == "" || userID == "" {
+		t.Skip("DATABASE_URL and IMPORT_USER_ID must be set")
+	}
+
+	t.Log("📥 Testing content import (VALIDATION MODE - ROLLBACK)...")
+
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	req := ContentImportRequest{
+		UserID:      userID,
+		CreateGroup: true,
+		Title:       "Import integration test",
+		Tags:        []string{"import-test"},
+		Source:      ContentImportSource{Type: "urls", URLs: []string{"https://example.com/a", "https://example.com/b"}},
+	}
+	batch, err := collectImportItems(req.Source, newFetchClient(importLinkTimeout, checkFetchAddress))
+	if err != nil {
+		t.Fatalf("Failed to collect items: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback() // ALWAYS ROLLBACK - validation only
+
+	result, err := importContent(tx, req, batch)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	var groupName, role string
+	if err := tx.QueryRow(`SELECT g.name, m.role FROM groups g JOIN group_memberships m ON m.group_id = g.id AND m.user_id = $2 WHERE g.id = $1`, result.GroupID, userID).Scan(&groupName, &role); err != nil {
+		t.Fatalf("Failed to load created group: %v", err)
+	}
+	if groupName != req.Title || role != "admin" {
+		t.Errorf("Expected group %q with admin membership, got %q (%s)", req.Title, groupName, role)
+	}
+
+	var childCount int
+	if err := tx.QueryRow(`SELECT count(*) FROM content WHERE parent_content_id = $1 AND group_id = $2`, result.ParentContentID, result.GroupID).Scan(&childCount); err != nil {
+		t.Fatalf("Failed to count imported content: %v", err)
+	}
+	if childCount != 2 || len(result.ContentIDs) != 2 {
+		t.Errorf("Expected 2 imported items, got %d rows and %d IDs", childCount, len(result.ContentIDs))
+	}
+
+	var taggedCount int
+	if err := tx.QueryRow(`
+		SELECT count(*) FROM content_tags ct JOIN tags tg ON tg.id = ct.tag_id
+		WHERE tg.name = 'import-test' AND tg.user_id = $1 AND ct.content_id = ANY($2::uuid[])`,
+		userID, "{"+result.ParentContentID+","+strings.Join(result.ContentIDs, ",")+"}").Scan(&taggedCount); err != nil {
+		t.Fatalf("Failed to count tags: %v", err)
+	}
+	if taggedCount != 3 {
+		t.Errorf("Expected the parent and 2 items tagged, got %d", taggedCount)
+	}
+
+	t.Log("✅ Database validation passed - all data will be rolled back")
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestContentSearchValidation tests request validation before any database access
+func TestContentSearchValidation(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+
+	testCases := []struct {
+		request     ContentSearchRequest
+		expectedErr string
+		desc        string
+	}{
+		{
+			request:     ContentSearchRequest{Query: "matrix"},
+			expectedErr: "group_id",
+			desc:        "missing group_id",
+		},
+		{
+			request:     ContentSearchRequest{GroupID: "not-a-uuid", Query: "matrix"},
+			expectedErr: "group_id",
+			desc:        "malformed group_id",
+		},
+		{
+			request:     ContentSearchRequest{GroupID: "6f1c2d3e-4a5b-4c6d-8e7f-9a0b1c2d3e4f", Query: "   "},
+			expectedErr: "query",
+			desc:        "blank query",
+		},
+		{
+			request:     ContentSearchRequest{GroupID: "6f1c2d3e-4a5b-4c6d-8e7f-9a0b1c2d3e4f", Query: "matrix"},
+			expectedErr: "DATABASE_URL",
+			desc:        "valid request without database configured",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			reqJSON, _ := json.Marshal(tc.request)
+			_, err := handleContentSearch(json.RawMessage(reqJSON))
+			if err == nil {
+				t.Fatalf("Expected error for %s, but got none", tc.desc)
+			}
+			if !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Fatalf("Expected error mentioning %q, got: %v", tc.expectedErr, err)
+			}
+
+			t.Logf("✓ Correctly returned error: %v", err)
+		})
+	}
+}
+
+// TestContentSearchIntegration runs a search against a live database.
+// Set DATABASE_URL and SEARCH_GROUP_ID to run it.
+func TestContentSearchIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	groupID := os.Getenv("SEARCH_GROUP_ID")
+	if os.Getenv("DATABASE_URL") == "" || groupID == "" {
+		t.Skip("DATABASE_URL and SEARCH_GROUP_ID must be set")
+	}
+
+	t.Log("🔎 Testing content search (LIVE DATABASE)...")
+
+	reqJSON, _ := json.Marshal(ContentSearchRequest{GroupID: groupID, Query: "the", Limit: 5, IncludeMetadata: true})
+	result, err := handleContentSearch(json.RawMessage(reqJSON))
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	t.Logf("📊 Found %d results", len(result.Results))
+	for i, r := range result.Results {
+		t.Logf("  %d. [%s] %.4f %s", i+1, r.Type, r.Rank, r.Snippet)
+	}
+}
+
+// TestContentMoveValidation tests request validation before any database access
+func TestContentMoveValidation(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+
+	contentID := "6f1c2d3e-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+	sameIDUpper := strings.ToUpper(contentID)
+	badParent := "not-a-uuid"
+	parentID := "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+
+	testCases := []struct {
+		request     ContentMoveRequest
+		expectedErr string
+		desc        string
+	}{
+		{
+			request:     ContentMoveRequest{},
+			expectedErr: "content_id",
+			desc:        "missing content_id",
+		},
+		{
+			request:     ContentMoveRequest{ContentID: contentID, NewParentID: &badParent},
+			expectedErr: "new_parent_id",
+			desc:        "malformed new_parent_id",
+		},
+		{
+			request:     ContentMoveRequest{ContentID: contentID, NewParentID: &sameIDUpper},
+			expectedErr: "under itself",
+			desc:        "moving content under itself",
+		},
+		{
+			request:     ContentMoveRequest{ContentID: contentID, NewParentID: &parentID},
+			expectedErr: "DATABASE_URL",
+			desc:        "valid move without database configured",
+		},
+		{
+			request:     ContentMoveRequest{ContentID: contentID},
+			expectedErr: "DATABASE_URL",
+			desc:        "valid move to top level without database configured",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			reqJSON, _ := json.Marshal(tc.request)
+			_, err := handleContentMove(json.RawMessage(reqJSON))
+			if err == nil {
+				t.Fatalf("Expected error for %s, but got none", tc.desc)
+			}
+			if !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Fatalf("Expected error mentioning %q, got: %v", tc.expectedErr, err)
+			}
+
+			t.Logf("✓ Correctly returned error: %v", err)
+		})
+	}
+}
+
+// recordingExecer records statements instead of running them
+type recordingExecer struct {
+	queries []string
+	args    [][]interface{}
+	failOn  string
+}
+
+func (r *recordingExecer) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if r.failOn != "" && strings.Contains(query, r.failOn) {
+		return nil, errors.New("connection reset")
+	}
+	r.queries = append(r.queries, query)
+	r.args = append(r.args, args)
+	return nil, nil
+}
+
+// parentArg renders a *string parent argument for comparison
+func parentArg(arg interface{}) string {
+	parentID, ok := arg.(*string)
+	if !ok {
+		return "unexpected type"
+	}
+	return formatParentID(parentID)
+}
+
+// TestMoveContentRelationship tests that a move replaces the old relationship row with the new one
+func TestMoveContentRelationship(t *testing.T) {
+	contentID := "6f1c2d3e-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+	oldParent := "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+	newParent := "1b2c3d4e-5f6a-4b7c-8d9e-0f1a2b3c4d5e"
+
+	testCases := []struct {
+		oldParentID *string
+		newParentID *string
+		failOn      string
+		expectedErr string
+		expectedOld string
+		expectedNew string
+		desc        string
+	}{
+		{oldParentID: &oldParent, newParentID: &newParent, expectedOld: oldParent, expectedNew: newParent, desc: "child moved to another parent"},
+		{oldParentID: nil, newParentID: &newParent, expectedOld: "top level", expectedNew: newParent, desc: "top-level item moved under a parent"},
+		{oldParentID: &oldParent, newParentID: nil, expectedOld: oldParent, expectedNew: "top level", desc: "child moved to top level"},
+		{oldParentID: &oldParent, newParentID: &newParent, failOn: "INSERT", expectedErr: "failed to create content relationship", desc: "insert failure is returned"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			execer := &recordingExecer{failOn: tc.failOn}
+			err := moveContentRelationship(execer, contentID, tc.oldParentID, tc.newParentID)
+
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("Expected error containing %q, got: %v", tc.expectedErr, err)
+				}
+				t.Logf("✓ Correctly returned error: %v", err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(execer.queries) != 2 {
+				t.Fatalf("Expected 2 statements, got %d", len(execer.queries))
+			}
+			if !strings.Contains(execer.queries[0], "DELETE FROM content_relationships") || !strings.Contains(execer.queries[0], "IS NOT DISTINCT FROM") {
+				t.Errorf("Expected first statement to delete the old relationship, got: %s", execer.queries[0])
+			}
+			if !strings.Contains(execer.queries[1], "INSERT INTO content_relationships") {
+				t.Errorf("Expected second statement to insert the new relationship, got: %s", execer.queries[1])
+			}
+
+			if got := parentArg(execer.args[0][0]); got != tc.expectedOld {
+				t.Errorf("Expected old parent %q, got %q", tc.expectedOld, got)
+			}
+			if got := parentArg(execer.args[1][0]); got != tc.expectedNew {
+				t.Errorf("Expected new parent %q, got %q", tc.expectedNew, got)
+			}
+			for i, args := range execer.args {
+				if args[1] != contentID {
+					t.Errorf("Expected statement %d to target %s, got %v", i, contentID, args[1])
+				}
+			}
+
+			t.Logf("✓ Validated: %s", tc.desc)
+		})
+	}
+}
+
+// TestContentTreeValidation tests request validation before any database access
+func TestContentTreeValidation(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+
+	testCases := []struct {
+		request     ContentTreeRequest
+		expectedErr string
+		desc        string
+	}{
+		{
+			request:     ContentTreeRequest{},
+			expectedErr: "group_id",
+			desc:        "missing group_id",
+		},
+		{
+			request:     ContentTreeRequest{GroupID: "6f1c2d3e-4a5b-4c6d-8e7f-9a0b1c2d3e4f", RootID: "not-a-uuid"},
+			expectedErr: "root_id",
+			desc:        "malformed root_id",
+		},
+		{
+			request:     ContentTreeRequest{GroupID: "6f1c2d3e-4a5b-4c6d-8e7f-9a0b1c2d3e4f", MaxDepth: 100, MaxNodes: -1},
+			expectedErr: "DATABASE_URL",
+			desc:        "valid request without database configured",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			reqJSON, _ := json.Marshal(tc.request)
+			_, err := handleContentTree(json.RawMessage(reqJSON))
+			if err == nil {
+				t.Fatalf("Expected error for %s, but got none", tc.desc)
+			}
+			if !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Fatalf("Expected error mentioning %q, got: %v", tc.expectedErr, err)
+			}
+
+			t.Logf("✓ Correctly returned error: %v", err)
+		})
+	}
+}
+
+// TestBuildContentTree tests nesting, sibling order and truncated counts
+func TestBuildContentTree(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := func(id, parentID string, depth, minute, childCount int) contentTreeRow {
+		return contentTreeRow{
+			node:     ContentTreeNode{ID: id, Type: "text", Data: id, ChildCount: childCount},
+			parentID: parentID,
+			depth:    depth,
+			created:  base.Add(time.Duration(minute) * time.Minute),
+		}
+	}
+
+	// Rows arrive in arbitrary order within a level, as from the unordered query.
+	// "list-b" has three children but only two made it under the node limit, and
+	// "note" sits at the depth limit with children that were never loaded.
+	rows := []contentTreeRow{
+		row("video-2", "list-b", 1, 5, 0),
+		row("list-b", "", 0, 2, 3),
+		row("note", "list-a", 1, 3, 4),
+		row("list-a", "", 0, 1, 1),
+		row("video-1", "list-b", 1, 4, 0),
+	}
+
+	roots, truncated := buildContentTree(rows)
+
+	if !truncated {
+		t.Errorf("Expected truncated tree")
+	}
+	if len(roots) != 2 || roots[0].ID != "list-a" || roots[1].ID != "list-b" {
+		t.Fatalf("Expected roots [list-a list-b], got %+v", roots)
+	}
+
+	listA, listB := roots[0], roots[1]
+	if len(listA.Children) != 1 || listA.Children[0].ID != "note" || listA.TruncatedCount != 0 {
+		t.Errorf("Unexpected list-a: %+v", listA)
+	}
+	if note := listA.Children[0]; note.TruncatedCount != 4 || len(note.Children) != 0 {
+		t.Errorf("Expected note to report 4 truncated children, got %+v", note)
+	}
+	if len(listB.Children) != 2 || listB.Children[0].ID != "video-1" || listB.Children[1].ID != "video-2" {
+		t.Errorf("Expected list-b children [video-1 video-2] in creation order, got %+v", listB.Children)
+	}
+	if listB.TruncatedCount != 1 {
+		t.Errorf("Expected list-b to report 1 truncated child, got %d", listB.TruncatedCount)
+	}
+
+	t.Log("✓ Tree nested in creation order with truncated counts")
+}
+
+// TestLimitTreeRows tests that only rows past max_nodes mark the tree truncated
+func TestLimitTreeRows(t *testing.T) {
+	rows := []contentTreeRow{
+		{node: ContentTreeNode{ID: "a"}},
+		{node: ContentTreeNode{ID: "b"}},
+		{node: ContentTreeNode{ID: "c"}},
+	}
+
+	testCases := []struct {
+		maxNodes          int
+		expectedCount     int
+		expectedTruncated bool
+		desc              string
+	}{
+		{maxNodes: 5, expectedCount: 3, expectedTruncated: false, desc: "fewer rows than the limit"},
+		{maxNodes: 3, expectedCount: 3, expectedTruncated: false, desc: "exactly the limit is complete"},
+		{maxNodes: 2, expectedCount: 2, expectedTruncated: true, desc: "extra row is trimmed"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			limited, truncated := limitTreeRows(rows, tc.maxNodes)
+			if len(limited) != tc.expectedCount || truncated != tc.expectedTruncated {
+				t.Fatalf("Expected %d rows (truncated=%v), got %d (truncated=%v)", tc.expectedCount, tc.expectedTruncated, len(limited), truncated)
+			}
+			t.Logf("✓ Validated: %s", tc.desc)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Error codes carried in Response.ErrorCode so callers can branch without
+// parsing the human-readable message
+const (
+	errCodeInvalidRequest = "invalid_request"
+	errCodeNotFound       = "not_found"
+	errCodeRateLimited    = "rate_limited"
+	errCodeTimeout        = "timeout"
+	errCodeUpstream       = "upstream_error"
+	errCodeInternal       = "internal_error"
+)
+
+// codedError attaches an error code to an error without changing its message
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// withErrorCode tags err with code; the outermost code wins in errorCode
+func withErrorCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// invalidRequest formats an error for a request the caller must fix
+func invalidRequest(format string, args ...interface{}) error {
+	return withErrorCode(errCodeInvalidRequest, fmt.Errorf(format, args...))
+}
+
+// statusErrorCode maps an upstream HTTP status to an error code
+func statusErrorCode(status int) string {
+	switch status {
+	case http.StatusNotFound, http.StatusGone:
+		return errCodeNotFound
+	case http.StatusTooManyRequests:
+		return errCodeRateLimited
+	default:
+		return errCodeUpstream
+	}
+}
+
+// errorCode classifies err for Response.ErrorCode. Explicitly tagged errors keep
+// their code; anything else is a timeout or an upstream failure.
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+
+	if errors.Is(err, errYouTubeRateLimited) {
+		return errCodeRateLimited
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errCodeTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return errCodeTimeout
+	}
+
+	return errCodeUpstream
+}
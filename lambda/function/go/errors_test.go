@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kkdai/youtube/v2"
+)
+
+// TestErrorCode tests how handler errors are classified for Response.ErrorCode
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		err          error
+		expectedCode string
+		desc         string
+	}{
+		{
+			err:          nil,
+			expectedCode: "",
+			desc:         "no error has no code",
+		},
+		{
+			err:          invalidRequest("url field is required"),
+			expectedCode: errCodeInvalidRequest,
+			desc:         "validation error",
+		},
+		{
+			err:          fmt.Errorf("failed to get video: %w", describeYouTubeError(youtube.ErrVideoPrivate)),
+			expectedCode: errCodeNotFound,
+			desc:         "wrapped private video",
+		},
+		{
+			err:          describeYouTubeError(youtube.ErrUnexpectedStatusCode(429)),
+			expectedCode: errCodeRateLimited,
+			desc:         "YouTube rate limit",
+		},
+		{
+			err:          withErrorCode(statusErrorCode(404), fmt.Errorf("fetch returned status 404")),
+			expectedCode: errCodeNotFound,
+			desc:         "upstream 404",
+		},
+		{
+			err:          withErrorCode(statusErrorCode(502), fmt.Errorf("fetch returned status 502")),
+			expectedCode: errCodeUpstream,
+			desc:         "upstream 502",
+		},
+		{
+			err:          fmt.Errorf("failed to fetch feed: %w", context.DeadlineExceeded),
+			expectedCode: errCodeTimeout,
+			desc:         "deadline exceeded",
+		},
+		{
+			err:          fmt.Errorf("failed to parse feed XML: unexpected EOF"),
+			expectedCode: errCodeUpstream,
+			desc:         "untagged error defaults to upstream",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			code := errorCode(tc.err)
+			if code != tc.expectedCode {
+				t.Errorf("Expected code %q, got %q for error: %v", tc.expectedCode, code, tc.err)
+			}
+
+			t.Logf("✓ Validated: %s", tc.desc)
+		})
+	}
+}
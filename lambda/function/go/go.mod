@@ -7,6 +7,8 @@ toolchain go1.24.4
 require (
 	github.com/PuerkitoBio/goquery v1.10.3
 	github.com/kkdai/youtube/v2 v2.10.4
+	github.com/lib/pq v1.10.9
+	golang.org/x/net v0.39.0
 )
 
 require (
@@ -16,6 +18,5 @@ require (
 	github.com/dop251/goja v0.0.0-20250125213203-5ef83b82af17 // indirect
 	github.com/go-sourcemap/sourcemap v2.1.4+incompatible // indirect
 	github.com/google/pprof v0.0.0-20250208200701-d0013a598941 // indirect
-	golang.org/x/net v0.39.0 // indirect
 	golang.org/x/text v0.24.0 // indirect
 )
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	defaultLinkTimeoutSeconds = 10
+	maxLinkBodyBytes          = 2 << 20 // 2 MB is plenty to reach the <head> of any page
+	maxLinkRedirects          = 5
+	linkUserAgent             = "Mozilla/5.0 (compatible; ListBot/1.0; +https://github.com/breadchris/list)"
+)
+
+// handleLinkMetadata fetches a URL and extracts OpenGraph/Twitter-card/HTML metadata
+func handleLinkMetadata(params json.RawMessage) (*LinkMetadataResponse, error) {
+	var req LinkMetadataRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, invalidRequest("invalid link metadata request: %w", err)
+	}
+
+	timeout := time.Duration(req.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultLinkTimeoutSeconds * time.Second
+	}
+
+	return fetchLinkMetadata(context.Background(), newFetchClient(timeout, checkFetchAddress), req.URL)
+}
+
+// fetchLinkMetadata fetches rawURL with client and extracts its metadata
+func fetchLinkMetadata(ctx context.Context, client *http.Client, rawURL string) (*LinkMetadataResponse, error) {
+	if rawURL == "" {
+		return nil, invalidRequest("url field is required")
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return nil, invalidRequest("invalid URL: %s", rawURL)
+	}
+
+	fmt.Fprintf(os.Stderr, "DEBUG: Fetching link metadata for: %s\n", rawURL)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("User-Agent", linkUserAgent)
+	httpReq.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, withErrorCode(statusErrorCode(resp.StatusCode), fmt.Errorf("fetch returned status %d", resp.StatusCode))
+	}
+
+	finalURL := resp.Request.URL
+
+	// Non-HTML resources (PDFs, images) have no tags to parse; return what we know
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return &LinkMetadataResponse{
+			URL:      finalURL.String(),
+			Title:    lastPathSegment(finalURL),
+			SiteName: finalURL.Hostname(),
+			Favicon:  resolveLinkURL(finalURL, "/favicon.ico"),
+		}, nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(io.LimitReader(resp.Body, maxLinkBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	return extractLinkMetadata(doc, finalURL), nil
+}
+
+// extractLinkMetadata reads metadata from a parsed document, preferring OpenGraph,
+// then Twitter cards, then plain HTML tags. Relative URLs are resolved against pageURL.
+func extractLinkMetadata(doc *goquery.Document, pageURL *url.URL) *LinkMetadataResponse {
+	meta := func(keys ...string) string {
+		for _, key := range keys {
+			var value string
+			doc.Find("meta").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+				name, _ := s.Attr("property")
+				if name == "" {
+					name, _ = s.Attr("name")
+				}
+				if strings.EqualFold(name, key) {
+					value = strings.TrimSpace(s.AttrOr("content", ""))
+					return value == ""
+				}
+				return true
+			})
+			if value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	title := meta("og:title", "twitter:title")
+	if title == "" {
+		title = strings.TrimSpace(doc.Find("title").First().Text())
+	}
+
+	siteName := meta("og:site_name", "application-name")
+	if siteName == "" {
+		siteName = pageURL.Hostname()
+	}
+
+	image := meta("og:image", "og:image:url", "twitter:image", "twitter:image:src")
+	if image != "" {
+		image = resolveLinkURL(pageURL, image)
+	}
+
+	favicon := ""
+	doc.Find("link[rel]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		for _, rel := range strings.Fields(strings.ToLower(s.AttrOr("rel", ""))) {
+			if rel == "icon" || rel == "apple-touch-icon" {
+				if href := strings.TrimSpace(s.AttrOr("href", "")); href != "" {
+					favicon = resolveLinkURL(pageURL, href)
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if favicon == "" {
+		favicon = resolveLinkURL(pageURL, "/favicon.ico")
+	}
+
+	return &LinkMetadataResponse{
+		URL:         pageURL.String(),
+		Title:       title,
+		Description: meta("og:description", "twitter:description", "description"),
+		Image:       image,
+		SiteName:    siteName,
+		Favicon:     favicon,
+	}
+}
+
+// newFetchClient returns an HTTP client for fetching arbitrary user-supplied URLs,
+// with an overall timeout and a bounded number of http(s)-only redirects.
+// checkAddress runs on the resolved IP:port of every connection, so it also
+// covers redirects and hostnames that resolve to internal addresses; handlers
+// pass checkFetchAddress.
+func newFetchClient(timeout time.Duration, checkAddress func(address string) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			return checkAddress(address)
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy: the dial check must see the target host, not a proxy
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			ForceAttemptHTTP2:   true,
+		},
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			if len(via) >= maxLinkRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxLinkRedirects)
+			}
+			if r.URL.Scheme != "http" && r.URL.Scheme != "https" {
+				return fmt.Errorf("refusing to follow redirect to %s", r.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// checkFetchAddress rejects a dial to an address user-supplied URLs must not
+// reach, such as the Lambda metadata endpoint or services inside the VPC
+func checkFetchAddress(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid dial address %q: %w", address, err)
+	}
+	ip := addrPort.Addr().Unmap()
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return invalidRequest("refusing to fetch from non-public address %s", ip)
+	}
+	return nil
+}
+
+// resolveLinkURL resolves ref against base, returning ref unchanged if it can't be parsed
+func resolveLinkURL(base *url.URL, ref string) string {
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(refURL).String()
+}
+
+// lastPathSegment returns the final path element of a URL, or its host for bare domains
+func lastPathSegment(u *url.URL) string {
+	path := strings.Trim(u.Path, "/")
+	if path == "" {
+		return u.Hostname()
+	}
+	segments := strings.Split(path, "/")
+	return segments[len(segments)-1]
+}
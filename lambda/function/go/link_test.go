@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// TestExtractLinkMetadata tests metadata precedence and URL resolution on fixed HTML
+func TestExtractLinkMetadata(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/posts/hello")
+
+	testCases := []struct {
+		html     string
+		expected LinkMetadataResponse
+		desc     string
+	}{
+		{
+			html: `<html><head>
+				<title>Plain Title</title>
+				<meta property="og:title" content="OG Title">
+				<meta name="twitter:title" content="Twitter Title">
+				<meta property="og:description" content="OG description">
+				<meta property="og:image" content="/img/cover.png">
+				<meta property="og:site_name" content="Example Blog">
+				<link rel="shortcut icon" href="/static/icon.png">
+			</head></html>`,
+			expected: LinkMetadataResponse{
+				URL:         "https://example.com/posts/hello",
+				Title:       "OG Title",
+				Description: "OG description",
+				Image:       "https://example.com/img/cover.png",
+				SiteName:    "Example Blog",
+				Favicon:     "https://example.com/static/icon.png",
+			},
+			desc: "OpenGraph tags take precedence",
+		},
+		{
+			html: `<html><head>
+				<title>Plain Title</title>
+				<meta name="twitter:title" content="Twitter Title">
+				<meta name="twitter:image" content="https://cdn.example.com/t.jpg">
+				<meta name="description" content="Meta description">
+			</head></html>`,
+			expected: LinkMetadataResponse{
+				URL:         "https://example.com/posts/hello",
+				Title:       "Twitter Title",
+				Description: "Meta description",
+				Image:       "https://cdn.example.com/t.jpg",
+				SiteName:    "example.com",
+				Favicon:     "https://example.com/favicon.ico",
+			},
+			desc: "Twitter card fallback with default favicon",
+		},
+		{
+			html: `<html><head><title>  Only a Title  </title></head><body></body></html>`,
+			expected: LinkMetadataResponse{
+				URL:      "https://example.com/posts/hello",
+				Title:    "Only a Title",
+				SiteName: "example.com",
+				Favicon:  "https://example.com/favicon.ico",
+			},
+			desc: "bare page falls back to <title> and hostname",
+		},
+		{
+			html: `<html><head>
+				<meta property="og:title" content="">
+				<title>Fallback</title>
+				<link rel="apple-touch-icon" href="touch.png">
+			</head></html>`,
+			expected: LinkMetadataResponse{
+				URL:      "https://example.com/posts/hello",
+				Title:    "Fallback",
+				SiteName: "example.com",
+				Favicon:  "https://example.com/posts/touch.png",
+			},
+			desc: "empty og:title is ignored and relative icon resolved",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tc.html))
+			if err != nil {
+				t.Fatalf("Failed to parse fixture: %v", err)
+			}
+
+			got := extractLinkMetadata(doc, pageURL)
+			if *got != tc.expected {
+				t.Fatalf("Metadata mismatch:\n  Expected: %+v\n  Got:      %+v", tc.expected, *got)
+			}
+
+			t.Logf("✓ Validated: %s", tc.desc)
+		})
+	}
+}
+
+// newTestFetchClient returns a fetch client that may reach httptest servers on loopback
+func newTestFetchClient() *http.Client {
+	return newFetchClient(5*time.Second, func(string) error { return nil })
+}
+
+// newOfflineFetchClient returns a fetch client that refuses every connection
+func newOfflineFetchClient() *http.Client {
+	return newFetchClient(time.Second, func(string) error { return errors.New("network disabled in test") })
+}
+
+// TestLinkMetadataHandler tests fetching metadata from a local server, including redirects
+func TestLinkMetadataHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/article", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><meta property="og:title" content="Redirected Article"></head></html>`))
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/paper.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.4"))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	testCases := []struct {
+		request       LinkMetadataRequest
+		expectedTitle string
+		expectedURL   string
+		shouldError   bool
+		desc          string
+	}{
+		{
+			request:       LinkMetadataRequest{URL: server.URL + "/old"},
+			expectedTitle: "Redirected Article",
+			expectedURL:   server.URL + "/article",
+			desc:          "follows redirects and reports final URL",
+		},
+		{
+			request:       LinkMetadataRequest{URL: server.URL + "/paper.pdf"},
+			expectedTitle: "paper.pdf",
+			expectedURL:   server.URL + "/paper.pdf",
+			desc:          "non-HTML content uses filename as title",
+		},
+		{
+			request:     LinkMetadataRequest{URL: server.URL + "/loop"},
+			shouldError: true,
+			desc:        "redirect loop is cut off",
+		},
+		{
+			request:     LinkMetadataRequest{URL: server.URL + "/missing"},
+			shouldError: true,
+			desc:        "non-200 status is an error",
+		},
+		{
+			request:     LinkMetadataRequest{URL: "ftp://example.com/file"},
+			shouldError: true,
+			desc:        "non-HTTP scheme is rejected",
+		},
+		{
+			request:     LinkMetadataRequest{},
+			shouldError: true,
+			desc:        "empty URL is rejected",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			result, err := fetchLinkMetadata(context.Background(), newTestFetchClient(), tc.request.URL)
+
+			if tc.shouldError {
+				if err == nil {
+					t.Fatalf("Expected error for %s, but got none", tc.desc)
+				}
+				t.Logf("✓ Correctly returned error: %v", err)
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error for %s: %v", tc.desc, err)
+			}
+
+			if result.Title != tc.expectedTitle {
+				t.Errorf("Expected title %q, got %q", tc.expectedTitle, result.Title)
+			}
+			if result.URL != tc.expectedURL {
+				t.Errorf("Expected URL %q, got %q", tc.expectedURL, result.URL)
+			}
+
+			t.Logf("✓ Validated: %s", tc.desc)
+		})
+	}
+}
+
+// TestCheckFetchAddress tests which resolved addresses the fetch client may dial
+func TestCheckFetchAddress(t *testing.T) {
+	testCases := []struct {
+		address     string
+		shouldError bool
+		desc        string
+	}{
+		{address: "93.184.216.34:443", desc: "public IPv4"},
+		{address: "[2606:4700::1111]:443", desc: "public IPv6"},
+		{address: "127.0.0.1:80", shouldError: true, desc: "loopback"},
+		{address: "[::1]:80", shouldError: true, desc: "IPv6 loopback"},
+		{address: "[::ffff:127.0.0.1]:80", shouldError: true, desc: "IPv4-mapped loopback"},
+		{address: "169.254.169.254:80", shouldError: true, desc: "link-local metadata endpoint"},
+		{address: "[fe80::1]:80", shouldError: true, desc: "IPv6 link-local"},
+		{address: "10.0.0.5:443", shouldError: true, desc: "private 10/8"},
+		{address: "172.16.3.4:443", shouldError: true, desc: "private 172.16/12"},
+		{address: "192.168.1.1:80", shouldError: true, desc: "private 192.168/16"},
+		{address: "[fd00::1]:80", shouldError: true, desc: "IPv6 unique local"},
+		{address: "0.0.0.0:80", shouldError: true, desc: "unspecified"},
+		{address: "224.0.0.1:80", shouldError: true, desc: "multicast"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := checkFetchAddress(tc.address)
+			if tc.shouldError {
+				if err == nil {
+					t.Fatalf("Expected %s to be rejected", tc.address)
+				}
+				if code := errorCode(err); code != errCodeInvalidRequest {
+					t.Errorf("Expected code %q, got %q", errCodeInvalidRequest, code)
+				}
+				t.Logf("✓ Correctly rejected: %v", err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected %s to be allowed, got: %v", tc.address, err)
+			}
+			t.Logf("✓ Allowed: %s", tc.address)
+		})
+	}
+}
+
+// TestFetchClientRejectsLoopback tests that link and feed fetches can't reach local services
+func TestFetchClientRejectsLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Loopback server should not have been reached: %s", r.URL)
+	}))
+	defer server.Close()
+
+	reqJSON, _ := json.Marshal(LinkMetadataRequest{URL: server.URL})
+	if _, err := handleLinkMetadata(json.RawMessage(reqJSON)); err == nil || errorCode(err) != errCodeInvalidRequest {
+		t.Fatalf("Expected link.metadata to reject loopback with invalid_request, got: %v", err)
+	}
+
+	reqJSON, _ = json.Marshal(RSSFetchRequest{URL: server.URL})
+	if _, err := handleRSSFetch(json.RawMessage(reqJSON)); err == nil || errorCode(err) != errCodeInvalidRequest {
+		t.Fatalf("Expected rss.fetch to reject loopback with invalid_request, got: %v", err)
+	}
+
+	t.Log("✓ Loopback fetches rejected")
+}
@@ -2,24 +2,55 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 )
 
+// maxRequestBytes bounds a single stdin line, which may hold a whole batch
+const maxRequestBytes = 16 << 20 // 16 MB
+
+// methodHandlers maps request methods to their handlers
+var methodHandlers = map[string]func(json.RawMessage) (interface{}, error){
+	"youtube.playlist":  func(p json.RawMessage) (interface{}, error) { return handlePlaylist(p) },
+	"youtube.subtitles": func(p json.RawMessage) (interface{}, error) { return handleSubtitles(p) },
+	"youtube.audio":     func(p json.RawMessage) (interface{}, error) { return handleAudio(p) },
+	"link.metadata":     func(p json.RawMessage) (interface{}, error) { return handleLinkMetadata(p) },
+	"rss.fetch":         func(p json.RawMessage) (interface{}, error) { return handleRSSFetch(p) },
+	"content.search":    func(p json.RawMessage) (interface{}, error) { return handleContentSearch(p) },
+	"content.move":      func(p json.RawMessage) (interface{}, error) { return handleContentMove(p) },
+	"content.tree":      func(p json.RawMessage) (interface{}, error) { return handleContentTree(p) },
+	"content.import":    func(p json.RawMessage) (interface{}, error) { return handleContentImport(p) },
+}
+
 func main() {
 	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRequestBytes)
 
 	for scanner.Scan() {
-		line := scanner.Bytes()
+		line := bytes.TrimSpace(scanner.Bytes())
+
+		// A line starting with '[' is a batch: an array of requests answered
+		// by an array of responses in the same order
+		if len(line) > 0 && line[0] == '[' {
+			var reqs []Request
+			if err := json.Unmarshal(line, &reqs); err != nil {
+				writeError(errCodeInvalidRequest, fmt.Sprintf("invalid JSON batch request: %v", err))
+				continue
+			}
+
+			writeBatchResponse(handleBatch(reqs))
+			continue
+		}
 
 		var req Request
 		if err := json.Unmarshal(line, &req); err != nil {
-			writeError(fmt.Sprintf("invalid JSON request: %v", err))
+			writeError(errCodeInvalidRequest, fmt.Sprintf("invalid JSON request: %v", err))
 			continue
 		}
 
-		handleRequest(req)
+		writeResponse(handleRequest(req))
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -28,63 +59,47 @@ func main() {
 	}
 }
 
-func handleRequest(req Request) {
-	switch req.Method {
-	case "youtube.playlist":
-		handlePlaylistRequest(req.Params)
-	case "youtube.subtitles":
-		handleSubtitlesRequest(req.Params)
-	default:
-		writeError(fmt.Sprintf("unknown method: %s", req.Method))
+// handleBatch runs each request in order; a failing item only fails its own response
+func handleBatch(reqs []Request) []Response {
+	responses := make([]Response, len(reqs))
+	for i, req := range reqs {
+		responses[i] = handleRequest(req)
 	}
+	return responses
 }
 
-func handlePlaylistRequest(params json.RawMessage) {
-	result, err := handlePlaylist(params)
-	if err != nil {
-		writeError(err.Error())
-		return
+func handleRequest(req Request) Response {
+	handler, ok := methodHandlers[req.Method]
+	if !ok {
+		return errorResponse(errCodeInvalidRequest, fmt.Sprintf("unknown method: %s", req.Method))
 	}
 
-	resultJSON, err := json.Marshal(result)
-	if err != nil {
-		writeError(fmt.Sprintf("failed to marshal result: %v", err))
-		return
-	}
-
-	writeSuccess(resultJSON)
-}
-
-func handleSubtitlesRequest(params json.RawMessage) {
-	result, err := handleSubtitles(params)
+	result, err := handler(req.Params)
 	if err != nil {
-		writeError(err.Error())
-		return
+		return errorResponse(errorCode(err), err.Error())
 	}
 
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
-		writeError(fmt.Sprintf("failed to marshal result: %v", err))
-		return
+		return errorResponse(errCodeInternal, fmt.Sprintf("failed to marshal result: %v", err))
 	}
 
-	writeSuccess(resultJSON)
-}
-
-func writeSuccess(result json.RawMessage) {
-	resp := Response{
+	return Response{
 		Success: true,
-		Result:  result,
+		Result:  resultJSON,
 	}
-	writeResponse(resp)
 }
 
-func writeError(message string) {
-	resp := Response{
-		Success: false,
-		Error:   message,
+func errorResponse(code, message string) Response {
+	return Response{
+		Success:   false,
+		Error:     message,
+		ErrorCode: code,
 	}
-	writeResponse(resp)
+}
+
+func writeError(code, message string) {
+	writeResponse(errorResponse(code, message))
 }
 
 func writeResponse(resp Response) {
@@ -96,3 +111,13 @@ func writeResponse(resp Response) {
 
 	fmt.Println(string(data))
 }
+
+func writeBatchResponse(resps []Response) {
+	data, err := json.Marshal(resps)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to marshal batch response: %v\n", err)
+		return
+	}
+
+	fmt.Println(string(data))
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHandleBatch tests that batch items are answered in order and fail independently
+func TestHandleBatch(t *testing.T) {
+	// Production handlers refuse loopback, so fetch the test server with a permissive client
+	linkMetadata := methodHandlers["link.metadata"]
+	methodHandlers["link.metadata"] = func(p json.RawMessage) (interface{}, error) {
+		var req LinkMetadataRequest
+		if err := json.Unmarshal(p, &req); err != nil {
+			return nil, invalidRequest("invalid link metadata request: %w", err)
+		}
+		return fetchLinkMetadata(context.Background(), newTestFetchClient(), req.URL)
+	}
+	defer func() { methodHandlers["link.metadata"] = linkMetadata }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Batch Page</title></head></html>`))
+	}))
+	defer server.Close()
+
+	batchJSON := `[
+		{"method": "link.metadata", "params": {"url": "` + server.URL + `"}},
+		{"method": "youtube.playlist", "params": {"url": ""}},
+		{"method": "no.such.method", "params": {}},
+		{"method": "youtube.subtitles", "params": "not an object"},
+		{"method": "link.metadata", "params": {"url": "` + server.URL + `/second"}}
+	]`
+
+	var reqs []Request
+	if err := json.Unmarshal([]byte(batchJSON), &reqs); err != nil {
+		t.Fatalf("Failed to parse batch fixture: %v", err)
+	}
+
+	responses := handleBatch(reqs)
+	if len(responses) != len(reqs) {
+		t.Fatalf("Expected %d responses, got %d", len(reqs), len(responses))
+	}
+
+	testCases := []struct {
+		index         int
+		expectSuccess bool
+		expectedError string
+		expectedCode  string
+		desc          string
+	}{
+		{index: 0, expectSuccess: true, desc: "first valid item succeeds"},
+		{index: 1, expectedError: "url field is required", expectedCode: errCodeInvalidRequest, desc: "missing param fails alone"},
+		{index: 2, expectedError: "unknown method: no.such.method", expectedCode: errCodeInvalidRequest, desc: "unknown method fails alone"},
+		{index: 3, expectedError: "invalid subtitle request: json: cannot unmarshal string into Go value of type main.SubtitleRequest", expectedCode: errCodeInvalidRequest, desc: "malformed params fail alone"},
+		{index: 4, expectSuccess: true, desc: "item after failures still succeeds"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			resp := responses[tc.index]
+
+			if resp.Success != tc.expectSuccess {
+				t.Fatalf("Expected success=%v, got %+v", tc.expectSuccess, resp)
+			}
+
+			if tc.expectSuccess {
+				var result LinkMetadataResponse
+				if err := json.Unmarshal(resp.Result, &result); err != nil {
+					t.Fatalf("Failed to decode result: %v", err)
+				}
+				if result.Title != "Batch Page" {
+					t.Errorf("Expected title %q, got %q", "Batch Page", result.Title)
+				}
+			} else {
+				if resp.Error != tc.expectedError {
+					t.Errorf("Expected error %q, got %q", tc.expectedError, resp.Error)
+				}
+				if resp.ErrorCode != tc.expectedCode {
+					t.Errorf("Expected error code %q, got %q", tc.expectedCode, resp.ErrorCode)
+				}
+			}
+
+			t.Logf("✓ Validated: %s", tc.desc)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+const maxFeedBodyBytes = 10 << 20 // 10 MB
+
+// XML structures for parsing RSS 2.0 feeds
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Author      string `xml:"author"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate     string `xml:"pubDate"`
+	DCDate      string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string `xml:"description"`
+}
+
+// XML structures for parsing Atom feeds
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomAuthor  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Links     []atomLink `xml:"link"`
+	Author    atomAuthor `xml:"author"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   string     `xml:"summary"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// feedDateLayouts covers the date formats seen in the wild for pubDate/published/updated
+var feedDateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// handleRSSFetch fetches an RSS or Atom feed and returns its entries
+func handleRSSFetch(params json.RawMessage) (*FeedResponse, error) {
+	var req RSSFetchRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, invalidRequest("invalid rss request: %w", err)
+	}
+
+	timeout := time.Duration(req.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultLinkTimeoutSeconds * time.Second
+	}
+
+	return fetchFeed(newFetchClient(timeout, checkFetchAddress), req)
+}
+
+// fetchFeed fetches and parses the feed at req.URL with client, keeping only
+// entries newer than req.Since when it is set
+func fetchFeed(client *http.Client, req RSSFetchRequest) (*FeedResponse, error) {
+	if req.URL == "" {
+		return nil, invalidRequest("url field is required")
+	}
+
+	parsedURL, err := url.Parse(req.URL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return nil, invalidRequest("invalid URL: %s", req.URL)
+	}
+
+	var since time.Time
+	if req.Since != "" {
+		since, err = parseFeedDate(req.Since)
+		if err != nil {
+			return nil, invalidRequest("invalid since date %q: use RFC3339 or YYYY-MM-DD", req.Since)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "DEBUG: Fetching feed: %s\n", req.URL)
+
+	httpReq, err := http.NewRequest(http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("User-Agent", linkUserAgent)
+	httpReq.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, withErrorCode(statusErrorCode(resp.StatusCode), fmt.Errorf("feed fetch returned status %d", resp.StatusCode))
+	}
+
+	// Read one byte past the limit so an oversized feed is reported, not parsed truncated
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	if len(body) > maxFeedBodyBytes {
+		return nil, fmt.Errorf("feed too large: more than %d MB", maxFeedBodyBytes>>20)
+	}
+
+	feed, err := parseFeed(body)
+	if err != nil {
+		return nil, err
+	}
+	feed.URL = resp.Request.URL.String()
+
+	if !since.IsZero() {
+		entries := make([]FeedEntry, 0, len(feed.Entries))
+		for _, entry := range feed.Entries {
+			// Undated entries are kept; the caller can't tell whether they're new
+			if published, err := parseFeedDate(entry.Published); err == nil && !published.After(since) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		fmt.Fprintf(os.Stderr, "DEBUG: %d of %d entries are newer than %s\n", len(entries), len(feed.Entries), req.Since)
+		feed.Entries = entries
+	}
+
+	return feed, nil
+}
+
+// parseFeed detects whether body is RSS 2.0 or Atom and normalizes it into a FeedResponse
+func parseFeed(body []byte) (*FeedResponse, error) {
+	root, err := feedRootElement(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed XML: %w", err)
+	}
+
+	switch root {
+	case "rss":
+		var doc rssDocument
+		if err := newFeedDecoder(body).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+		}
+
+		entries := make([]FeedEntry, 0, len(doc.Channel.Items))
+		for _, item := range doc.Channel.Items {
+			link := strings.TrimSpace(item.Link)
+			if link == "" && strings.HasPrefix(item.GUID, "http") {
+				link = strings.TrimSpace(item.GUID)
+			}
+			author := item.Author
+			if author == "" {
+				author = item.Creator
+			}
+			published := item.PubDate
+			if published == "" {
+				published = item.DCDate
+			}
+
+			entries = append(entries, FeedEntry{
+				Title:     strings.TrimSpace(item.Title),
+				URL:       link,
+				Author:    strings.TrimSpace(author),
+				Published: normalizeFeedDate(published),
+				Summary:   strings.TrimSpace(item.Description),
+			})
+		}
+
+		return &FeedResponse{
+			Title:       strings.TrimSpace(doc.Channel.Title),
+			Link:        strings.TrimSpace(doc.Channel.Link),
+			Description: strings.TrimSpace(doc.Channel.Description),
+			Format:      "rss",
+			Entries:     entries,
+		}, nil
+
+	case "feed":
+		var doc atomFeed
+		if err := newFeedDecoder(body).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to parse Atom feed: %w", err)
+		}
+
+		entries := make([]FeedEntry, 0, len(doc.Entries))
+		for _, entry := range doc.Entries {
+			author := entry.Author.Name
+			if author == "" {
+				author = doc.Author.Name
+			}
+			published := entry.Published
+			if published == "" {
+				published = entry.Updated
+			}
+
+			entries = append(entries, FeedEntry{
+				Title:     strings.TrimSpace(entry.Title),
+				URL:       atomAlternateLink(entry.Links),
+				Author:    strings.TrimSpace(author),
+				Published: normalizeFeedDate(published),
+				Summary:   strings.TrimSpace(entry.Summary),
+			})
+		}
+
+		return &FeedResponse{
+			Title:       strings.TrimSpace(doc.Title),
+			Link:        atomAlternateLink(doc.Links),
+			Description: strings.TrimSpace(doc.Subtitle),
+			Format:      "atom",
+			Entries:     entries,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported feed format: <%s>", root)
+	}
+}
+
+// newFeedDecoder returns an XML decoder for body that converts declared
+// encodings such as ISO-8859-1 and windows-1252 to UTF-8
+func newFeedDecoder(body []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	return decoder
+}
+
+// feedRootElement returns the local name of the document's root element
+func feedRootElement(body []byte) (string, error) {
+	decoder := newFeedDecoder(body)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// atomAlternateLink picks the rel="alternate" link (the default when rel is absent)
+func atomAlternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// parseFeedDate parses a feed timestamp in any of the supported layouts
+func parseFeedDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date format: %q", value)
+}
+
+// normalizeFeedDate formats a feed timestamp as ISO 8601. Unparseable values
+// are returned trimmed but otherwise as-is, so the date isn't lost.
+func normalizeFeedDate(value string) string {
+	t, err := parseFeedDate(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return t.Format("2006-01-02T15:04:05Z07:00")
+}
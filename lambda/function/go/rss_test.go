@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const rssFixture = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>Example Blog</title>
+    <link>https://blog.example.com/</link>
+    <description>Notes and essays</description>
+    <item>
+      <title>Newest Post</title>
+      <link>https://blog.example.com/newest</link>
+      <dc:creator>Ada</dc:creator>
+      <pubDate>Tue, 10 Jun 2025 09:00:00 +0000</pubDate>
+      <description>Latest thoughts</description>
+    </item>
+    <item>
+      <title>Older Post</title>
+      <guid>https://blog.example.com/older</guid>
+      <author>grace@example.com</author>
+      <pubDate>Mon, 2 Dec 2024 15:04:05 GMT</pubDate>
+    </item>
+    <item>
+      <title>Undated Post</title>
+      <link>https://blog.example.com/undated</link>
+    </item>
+  </channel>
+</rss>`
+
+const atomFixture = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Atom</title>
+  <subtitle>An Atom feed</subtitle>
+  <link rel="self" href="https://atom.example.com/feed.xml"/>
+  <link href="https://atom.example.com/"/>
+  <author><name>Feed Author</name></author>
+  <entry>
+    <title>First Entry</title>
+    <id>urn:uuid:1</id>
+    <link rel="alternate" href="https://atom.example.com/first"/>
+    <published>2025-03-01T12:00:00Z</published>
+    <summary>Summary one</summary>
+  </entry>
+  <entry>
+    <title>Second Entry</title>
+    <id>urn:uuid:2</id>
+    <link href="https://atom.example.com/second"/>
+    <author><name>Guest</name></author>
+    <updated>2024-01-15T08:30:00-05:00</updated>
+  </entry>
+</feed>`
+
+// TestParseFeed tests RSS 2.0 and Atom normalization into FeedResponse
+func TestParseFeed(t *testing.T) {
+	testCases := []struct {
+		body            string
+		expectedFormat  string
+		expectedTitle   string
+		expectedLink    string
+		expectedEntries []FeedEntry
+		shouldError     bool
+		desc            string
+	}{
+		{
+			body:           rssFixture,
+			expectedFormat: "rss",
+			expectedTitle:  "Example Blog",
+			expectedLink:   "https://blog.example.com/",
+			expectedEntries: []FeedEntry{
+				{Title: "Newest Post", URL: "https://blog.example.com/newest", Author: "Ada", Published: "2025-06-10T09:00:00Z", Summary: "Latest thoughts"},
+				{Title: "Older Post", URL: "https://blog.example.com/older", Author: "grace@example.com", Published: "2024-12-02T15:04:05Z"},
+				{Title: "Undated Post", URL: "https://blog.example.com/undated"},
+			},
+			desc: "RSS 2.0 with dc:creator and guid fallback",
+		},
+		{
+			body:           atomFixture,
+			expectedFormat: "atom",
+			expectedTitle:  "Example Atom",
+			expectedLink:   "https://atom.example.com/",
+			expectedEntries: []FeedEntry{
+				{Title: "First Entry", URL: "https://atom.example.com/first", Author: "Feed Author", Published: "2025-03-01T12:00:00Z", Summary: "Summary one"},
+				{Title: "Second Entry", URL: "https://atom.example.com/second", Author: "Guest", Published: "2024-01-15T08:30:00-05:00"},
+			},
+			desc: "Atom with feed-level author and updated fallback",
+		},
+		{
+			body:           `<rss version="2.0"><channel><title>Loose Dates</title><item><title>Spring</title><link>https://loose.example.com/spring</link><pubDate> Spring 2025 </pubDate></item></channel></rss>`,
+			expectedFormat: "rss",
+			expectedTitle:  "Loose Dates",
+			expectedEntries: []FeedEntry{
+				{Title: "Spring", URL: "https://loose.example.com/spring", Published: "Spring 2025"},
+			},
+			desc: "unparseable dates keep the raw value",
+		},
+		{
+			body:           "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><rss version=\"2.0\"><channel><title>Caf\xe9 Notes</title><item><title>Cr\xe8me br\xfbl\xe9e</title><link>https://cafe.example.com/creme</link></item></channel></rss>",
+			expectedFormat: "rss",
+			expectedTitle:  "Café Notes",
+			expectedEntries: []FeedEntry{
+				{Title: "Crème brûlée", URL: "https://cafe.example.com/creme"},
+			},
+			desc: "ISO-8859-1 feed is decoded to UTF-8",
+		},
+		{
+			body:        `<html><body>not a feed</body></html>`,
+			shouldError: true,
+			desc:        "HTML page is rejected",
+		},
+		{
+			body:        `not xml at all`,
+			shouldError: true,
+			desc:        "garbage is rejected",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			feed, err := parseFeed([]byte(tc.body))
+
+			if tc.shouldError {
+				if err == nil {
+					t.Fatalf("Expected error for %s, but got none", tc.desc)
+				}
+				t.Logf("✓ Correctly returned error: %v", err)
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error for %s: %v", tc.desc, err)
+			}
+
+			if feed.Format != tc.expectedFormat || feed.Title != tc.expectedTitle || feed.Link != tc.expectedLink {
+				t.Fatalf("Feed header mismatch: got format=%q title=%q link=%q", feed.Format, feed.Title, feed.Link)
+			}
+
+			if len(feed.Entries) != len(tc.expectedEntries) {
+				t.Fatalf("Expected %d entries, got %d", len(tc.expectedEntries), len(feed.Entries))
+			}
+
+			for i, expected := range tc.expectedEntries {
+				if feed.Entries[i] != expected {
+					t.Errorf("Entry %d mismatch:\n  Expected: %+v\n  Got:      %+v", i, expected, feed.Entries[i])
+				}
+			}
+
+			t.Logf("✓ Validated: %s", tc.desc)
+		})
+	}
+}
+
+// TestRSSFetchSince tests the since filter against a local feed server
+func TestRSSFetchSince(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(rssFixture))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		since          string
+		expectedTitles []string
+		shouldError    bool
+		desc           string
+	}{
+		{
+			since:          "",
+			expectedTitles: []string{"Newest Post", "Older Post", "Undated Post"},
+			desc:           "no since returns every entry",
+		},
+		{
+			since:          "2025-01-01",
+			expectedTitles: []string{"Newest Post", "Undated Post"},
+			desc:           "date-only since drops older entries but keeps undated",
+		},
+		{
+			since:          "2025-06-10T09:00:00Z",
+			expectedTitles: []string{"Undated Post"},
+			desc:           "since is exclusive",
+		},
+		{
+			since:       "last tuesday",
+			shouldError: true,
+			desc:        "unparseable since is rejected",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			feed, err := fetchFeed(newTestFetchClient(), RSSFetchRequest{URL: server.URL, Since: tc.since})
+
+			if tc.shouldError {
+				if err == nil {
+					t.Fatalf("Expected error for %s, but got none", tc.desc)
+				}
+				t.Logf("✓ Correctly returned error: %v", err)
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error for %s: %v", tc.desc, err)
+			}
+
+			if len(feed.Entries) != len(tc.expectedTitles) {
+				t.Fatalf("Expected %d entries, got %d", len(tc.expectedTitles), len(feed.Entries))
+			}
+			for i, title := range tc.expectedTitles {
+				if feed.Entries[i].Title != title {
+					t.Errorf("Entry %d: expected %q, got %q", i, title, feed.Entries[i].Title)
+				}
+			}
+
+			t.Logf("✓ Validated: %s", tc.desc)
+		})
+	}
+}
+
+// TestRSSFetchTooLarge tests that an oversized feed is rejected rather than parsed truncated
+func TestRSSFetchTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<rss version="2.0"><channel><title>`))
+		w.Write(bytes.Repeat([]byte("x"), maxFeedBodyBytes))
+		w.Write([]byte(`</title></channel></rss>`))
+	}))
+	defer server.Close()
+
+	_, err := fetchFeed(newTestFetchClient(), RSSFetchRequest{URL: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "feed too large") {
+		t.Fatalf("Expected feed too large error, got: %v", err)
+	}
+
+	t.Logf("✓ Correctly returned error: %v", err)
+}
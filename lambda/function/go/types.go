@@ -10,19 +10,31 @@ type Request struct {
 
 // Response represents an outgoing JSON-RPC style response
 type Response struct {
-	Success bool            `json:"success"`
-	Result  json.RawMessage `json:"result,omitempty"`
-	Error   string          `json:"error,omitempty"`
+	Success   bool            `json:"success"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	ErrorCode string          `json:"error_code,omitempty"` // One of the errCode* constants
 }
 
 // PlaylistRequest contains a YouTube playlist URL
 type PlaylistRequest struct {
-	URL string `json:"url"`
+	URL            string `json:"url"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // Overall deadline, defaults to 25 seconds, at most 240
+	MaxVideos      int    `json:"max_videos,omitempty"`      // Only the first N playlist entries; 0 for all
 }
 
 // PlaylistResponse contains the enumerated videos from a playlist
 type PlaylistResponse struct {
-	Videos []VideoInfo `json:"videos"`
+	Videos   []VideoInfo       `json:"videos"`
+	Failures []VideoFetchError `json:"failures"` // Videos returned with playlist entry data only
+}
+
+// VideoFetchError records a video whose full details couldn't be fetched
+type VideoFetchError struct {
+	VideoID   string `json:"video_id"`
+	Title     string `json:"title"`
+	Reason    string `json:"reason"`
+	ErrorCode string `json:"error_code"` // Same codes as Response.ErrorCode
 }
 
 // Thumbnail represents a single thumbnail image
@@ -49,7 +61,8 @@ type VideoInfo struct {
 
 // SubtitleRequest contains a YouTube video ID for subtitle extraction
 type SubtitleRequest struct {
-	VideoID string `json:"video_id"`
+	VideoID   string   `json:"video_id"`
+	Languages []string `json:"languages,omitempty"` // Only download these languages (prefix match, e.g. "en"); the first track if none match
 }
 
 // SubtitleTrack represents a single subtitle/caption track
@@ -67,3 +80,174 @@ type SubtitleResponse struct {
 	Tracks  []SubtitleTrack `json:"tracks"`
 }
 
+// LinkMetadataRequest contains a URL to enrich with page metadata
+type LinkMetadataRequest struct {
+	URL            string `json:"url"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // Defaults to 10 seconds
+}
+
+// LinkMetadataResponse contains metadata extracted from a page's OpenGraph/Twitter/HTML tags
+type LinkMetadataResponse struct {
+	URL         string `json:"url"`         // Final URL after redirects
+	Title       string `json:"title"`       // og:title, twitter:title, or <title>
+	Description string `json:"description"` // og:description, twitter:description, or meta description
+	Image       string `json:"image"`       // Absolute og:image/twitter:image URL
+	SiteName    string `json:"site_name"`   // og:site_name or hostname
+	Favicon     string `json:"favicon"`     // Absolute icon URL, defaults to /favicon.ico
+}
+
+// RSSFetchRequest contains an RSS or Atom feed URL
+type RSSFetchRequest struct {
+	URL            string `json:"url"`
+	Since          string `json:"since,omitempty"`           // RFC3339 or YYYY-MM-DD; only newer entries are returned
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // Defaults to 10 seconds
+}
+
+// FeedEntry represents a single item from an RSS or Atom feed
+type FeedEntry struct {
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Author    string `json:"author"`
+	Published string `json:"published"` // ISO 8601 formatted date; the feed's raw value if unparseable, empty if missing
+	Summary   string `json:"summary"`
+}
+
+// FeedResponse contains a feed's channel information and its entries
+type FeedResponse struct {
+	URL         string      `json:"url"`    // Final feed URL after redirects
+	Title       string      `json:"title"`  // Feed title, suitable as a group name
+	Link        string      `json:"link"`   // Website the feed belongs to
+	Format      string      `json:"format"` // "rss" or "atom"
+	Description string      `json:"description"`
+	Entries     []FeedEntry `json:"entries"`
+}
+
+// ContentSearchRequest contains a full-text query scoped to a group
+type ContentSearchRequest struct {
+	GroupID         string `json:"group_id"`
+	Query           string `json:"query"`            // websearch syntax: "exact phrase", -exclude, OR
+	Limit           int    `json:"limit,omitempty"`  // Defaults to 20, capped at 100
+	Offset          int    `json:"offset,omitempty"` // For paging through results
+	IncludeMetadata bool   `json:"include_metadata,omitempty"`
+}
+
+// ContentSearchResult represents a single ranked content match
+type ContentSearchResult struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Data            string          `json:"data"`
+	ParentContentID string          `json:"parent_content_id,omitempty"`
+	Metadata        json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt       string          `json:"created_at"` // ISO 8601 formatted date
+	Rank            float64         `json:"rank"`
+	Snippet         string          `json:"snippet"` // Highlighted excerpt with <b> around matches
+}
+
+// ContentSearchResponse contains ranked search results
+type ContentSearchResponse struct {
+	Query   string                `json:"query"`
+	Results []ContentSearchResult `json:"results"`
+}
+
+// ContentMoveRequest re-parents a content item; a null new_parent_id moves it to the top level
+type ContentMoveRequest struct {
+	ContentID   string  `json:"content_id"`
+	NewParentID *string `json:"new_parent_id"`
+}
+
+// ContentMoveResponse reports where a content item was moved from and to
+type ContentMoveResponse struct {
+	ContentID        string `json:"content_id"`
+	ParentContentID  string `json:"parent_content_id,omitempty"`  // Empty when moved to the top level
+	PreviousParentID string `json:"previous_parent_id,omitempty"` // Empty when it was at the top level
+}
+
+// ContentTreeRequest asks for a group's content hierarchy, optionally below one item
+type ContentTreeRequest struct {
+	GroupID  string `json:"group_id"`
+	RootID   string `json:"root_id,omitempty"`   // Subtree root; defaults to the group's top-level items
+	MaxDepth int    `json:"max_depth,omitempty"` // Levels below the roots, defaults to 5, capped at 20
+	MaxNodes int    `json:"max_nodes,omitempty"` // Defaults to 500, capped at 5000
+}
+
+// ContentTreeNode is a content item with its nested children
+type ContentTreeNode struct {
+	ID             string             `json:"id"`
+	Type           string             `json:"type"`
+	Data           string             `json:"data"`
+	Metadata       json.RawMessage    `json:"metadata,omitempty"`
+	CreatedAt      string             `json:"created_at"`      // ISO 8601 formatted date
+	ChildCount     int                `json:"child_count"`     // Direct children in the database
+	TruncatedCount int                `json:"truncated_count"` // Direct children left out by max_depth or max_nodes
+	Children       []*ContentTreeNode `json:"children"`
+}
+
+// ContentTreeResponse contains the nested content hierarchy
+type ContentTreeResponse struct {
+	GroupID   string             `json:"group_id"`
+	Roots     []*ContentTreeNode `json:"roots"`
+	NodeCount int                `json:"node_count"`
+	Truncated bool               `json:"truncated"` // True if children were left out or max_nodes was reached
+}
+
+// ContentImportRequest imports content from a source into a group, under a new
+// list or an existing parent
+type ContentImportRequest struct {
+	GroupID         string              `json:"group_id,omitempty"`          // Required unless create_group is set
+	UserID          string              `json:"user_id"`                     // Owner of the created content; must be a group member
+	CreateGroup     bool                `json:"create_group,omitempty"`      // Import into a new group named like the list, owned by user_id
+	ParentContentID string              `json:"parent_content_id,omitempty"` // Existing parent; otherwise a new list is created
+	Title           string              `json:"title,omitempty"`             // Data for the created list; defaults to the source's title, e.g. the feed's
+	Tags            []string            `json:"tags,omitempty"`              // Applied to the parent and every imported item
+	Source          ContentImportSource `json:"source"`
+}
+
+// ContentImportSource describes where imported content comes from
+type ContentImportSource struct {
+	Type    string              `json:"type"`               // "urls", "rss" or "items"
+	URLs    []string            `json:"urls,omitempty"`     // For urls: one content item per http(s) URL, with its link metadata
+	FeedURL string              `json:"feed_url,omitempty"` // For rss: one text item per entry URL, tagged rss
+	Since   string              `json:"since,omitempty"`    // For rss: only entries published after this date
+	Items   []ContentImportItem `json:"items,omitempty"`    // For items: pre-formatted content such as a Libgen selection or playlist videos
+}
+
+// ContentImportItem is a single content row to insert
+type ContentImportItem struct {
+	Type     string          `json:"type,omitempty"` // Defaults to text
+	Data     string          `json:"data"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// ContentImportResponse reports the content created by an import
+type ContentImportResponse struct {
+	GroupID         string   `json:"group_id"` // The created group when create_group is set
+	ParentContentID string   `json:"parent_content_id"`
+	ContentIDs      []string `json:"content_ids"`
+	Skipped         []string `json:"skipped"` // Inputs that were ignored, e.g. invalid URLs
+}
+
+// AudioRequest contains a YouTube video ID for audio stream extraction
+type AudioRequest struct {
+	VideoID string `json:"video_id"`
+}
+
+// AudioFormat represents a single audio-only stream
+type AudioFormat struct {
+	Itag          int    `json:"itag"`
+	URL           string `json:"url"`            // Direct stream URL; expires after a few hours
+	MimeType      string `json:"mime_type"`      // e.g. audio/mp4; codecs="mp4a.40.2"
+	Bitrate       int    `json:"bitrate"`        // Bits per second
+	AudioQuality  string `json:"audio_quality"`  // e.g. AUDIO_QUALITY_MEDIUM
+	SampleRate    string `json:"sample_rate"`    // Hz
+	Channels      int    `json:"channels"`       // Number of audio channels
+	ContentLength int64  `json:"content_length"` // Bytes, 0 if unknown
+}
+
+// AudioResponse contains the audio streams available for a video
+type AudioResponse struct {
+	VideoID  string        `json:"video_id"`
+	Title    string        `json:"title"`
+	Duration int64         `json:"duration"` // Duration in seconds
+	Best     AudioFormat   `json:"best"`     // Highest-bitrate stream
+	Formats  []AudioFormat `json:"formats"`  // All audio streams, best first
+}
@@ -10,11 +10,23 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/kkdai/youtube/v2"
 )
 
+const (
+	// Leaves headroom under go-executor.ts's default 30s process timeout
+	defaultPlaylistTimeoutSeconds = 25
+	// Below the Lambda's 300s timeout; callers asking for more than the default
+	// must raise the executor timeout to match, as getPlaylist does
+	maxPlaylistTimeoutSeconds = 240
+	playlistFetchWorkers      = 5
+)
+
 // normalizePlaylistURL extracts the playlist ID from various YouTube URL formats
 // and returns a clean playlist URL that the kkdai/youtube library can handle
 func normalizePlaylistURL(inputURL string) (string, error) {
@@ -49,11 +61,11 @@ func normalizePlaylistURL(inputURL string) (string, error) {
 func handlePlaylist(params json.RawMessage) (*PlaylistResponse, error) {
 	var req PlaylistRequest
 	if err := json.Unmarshal(params, &req); err != nil {
-		return nil, fmt.Errorf("invalid playlist request: %w", err)
+		return nil, invalidRequest("invalid playlist request: %w", err)
 	}
 
 	if req.URL == "" {
-		return nil, fmt.Errorf("url field is required")
+		return nil, invalidRequest("url field is required")
 	}
 
 	// Debug: Print the URL being processed
@@ -62,88 +74,170 @@ func handlePlaylist(params json.RawMessage) (*PlaylistResponse, error) {
 	// Normalize the URL to extract playlist ID
 	normalizedURL, err := normalizePlaylistURL(req.URL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid playlist URL: %w", err)
+		return nil, invalidRequest("invalid playlist URL: %w", err)
 	}
 
 	fmt.Fprintf(os.Stderr, "DEBUG: Normalized to: %s\n", normalizedURL)
 
-	client := youtube.Client{}
-	ctx := context.Background()
+	timeout := time.Duration(req.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultPlaylistTimeoutSeconds * time.Second
+	}
+	if timeout > maxPlaylistTimeoutSeconds*time.Second {
+		timeout = maxPlaylistTimeoutSeconds * time.Second
+	}
+
+	httpClient, err := newYouTubeHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	client := youtube.Client{HTTPClient: httpClient}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
 	// Get playlist from normalized URL
-	playlist, err := client.GetPlaylistContext(ctx, normalizedURL)
+	var playlist *youtube.Playlist
+	err = withYouTubeRetry(ctx, "playlist fetch", func() error {
+		var err error
+		playlist, err = client.GetPlaylistContext(ctx, normalizedURL)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get playlist: %w", err)
 	}
 
-	// Extract video information with full metadata
-	videos := make([]VideoInfo, 0, len(playlist.Videos))
-	for _, entry := range playlist.Videos {
-		// Attempt to fetch full Video object for richer metadata
-		video, err := client.VideoFromPlaylistEntryContext(ctx, entry)
+	entries := playlist.Videos
+	if req.MaxVideos > 0 && len(entries) > req.MaxVideos {
+		entries = entries[:req.MaxVideos]
+	}
 
-		var videoInfo VideoInfo
-		if err != nil {
-			// Fallback to PlaylistEntry data if full fetch fails
-			fmt.Fprintf(os.Stderr, "WARNING: Failed to fetch full video details for %s: %v. Using playlist entry data.\n", entry.ID, err)
-
-			// Convert thumbnails from PlaylistEntry
-			thumbnails := make([]Thumbnail, len(entry.Thumbnails))
-			for i, thumb := range entry.Thumbnails {
-				thumbnails[i] = Thumbnail{
-					URL:    thumb.URL,
-					Width:  thumb.Width,
-					Height: thumb.Height,
-				}
-			}
+	videos, failures := fetchPlaylistVideos(ctx, client.HTTPClient, entries)
+	if len(failures) > 0 {
+		fmt.Fprintf(os.Stderr, "WARNING: %d of %d videos fell back to playlist entry data\n", len(failures), len(entries))
+	}
 
-			videoInfo = VideoInfo{
-				ID:         entry.ID,
-				Title:      entry.Title,
-				URL:        fmt.Sprintf("https://www.youtube.com/watch?v=%s", entry.ID),
-				Duration:   int64(entry.Duration.Seconds()),
-				Author:     entry.Author,
-				Thumbnails: thumbnails,
-			}
-		} else {
-			// Use full Video object data
-			// Convert thumbnails
-			thumbnails := make([]Thumbnail, len(video.Thumbnails))
-			for i, thumb := range video.Thumbnails {
-				thumbnails[i] = Thumbnail{
-					URL:    thumb.URL,
-					Width:  thumb.Width,
-					Height: thumb.Height,
+	return &PlaylistResponse{
+		Videos:   videos,
+		Failures: failures,
+	}, nil
+}
+
+// fetchPlaylistVideos fetches full metadata for each entry using a bounded worker pool,
+// preserving playlist order. Entries whose fetch fails (including once ctx's deadline
+// passes) fall back to the playlist entry data and are reported as failures, also in
+// playlist order.
+func fetchPlaylistVideos(ctx context.Context, httpClient *http.Client, entries []*youtube.PlaylistEntry) ([]VideoInfo, []VideoFetchError) {
+	videos := make([]VideoInfo, len(entries))
+	errs := make([]error, len(entries))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < playlistFetchWorkers && w < len(entries); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// youtube.Client caches visitor/consent state without locking, so each worker gets its own
+			client := youtube.Client{HTTPClient: httpClient}
+			for i := range jobs {
+				entry := entries[i]
+
+				// Attempt to fetch full Video object for richer metadata
+				var video *youtube.Video
+				err := withYouTubeRetry(ctx, "video fetch", func() error {
+					var err error
+					video, err = client.VideoFromPlaylistEntryContext(ctx, entry)
+					return err
+				})
+				if err != nil {
+					// Fallback to PlaylistEntry data if full fetch fails
+					fmt.Fprintf(os.Stderr, "WARNING: Failed to fetch full video details for %s: %v. Using playlist entry data.\n", entry.ID, err)
+					videos[i] = videoInfoFromEntry(entry)
+					errs[i] = err
+					continue
 				}
-			}
 
-			// Format publish date as ISO 8601
-			publishDate := ""
-			if !video.PublishDate.IsZero() {
-				publishDate = video.PublishDate.Format("2006-01-02T15:04:05Z07:00")
+				videos[i] = videoInfoFromVideo(video)
 			}
+		}()
+	}
 
-			videoInfo = VideoInfo{
-				ID:            video.ID,
-				Title:         video.Title,
-				URL:           fmt.Sprintf("https://www.youtube.com/watch?v=%s", video.ID),
-				Duration:      int64(video.Duration.Seconds()),
-				Author:        video.Author,
-				ChannelID:     video.ChannelID,
-				ChannelHandle: video.ChannelHandle,
-				Description:   video.Description,
-				Views:         uint64(video.Views),
-				PublishDate:   publishDate,
-				Thumbnails:    thumbnails,
-			}
+	for i := range entries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	failures := []VideoFetchError{}
+	for i, err := range errs {
+		if err == nil {
+			continue
 		}
+		failures = append(failures, VideoFetchError{
+			VideoID:   entries[i].ID,
+			Title:     entries[i].Title,
+			Reason:    err.Error(),
+			ErrorCode: errorCode(err),
+		})
+	}
+
+	return videos, failures
+}
 
-		videos = append(videos, videoInfo)
+// videoInfoFromEntry builds VideoInfo from the limited data in a playlist entry
+func videoInfoFromEntry(entry *youtube.PlaylistEntry) VideoInfo {
+	// Convert thumbnails from PlaylistEntry
+	thumbnails := make([]Thumbnail, len(entry.Thumbnails))
+	for i, thumb := range entry.Thumbnails {
+		thumbnails[i] = Thumbnail{
+			URL:    thumb.URL,
+			Width:  thumb.Width,
+			Height: thumb.Height,
+		}
 	}
 
-	return &PlaylistResponse{
-		Videos: videos,
-	}, nil
+	return VideoInfo{
+		ID:         entry.ID,
+		Title:      entry.Title,
+		URL:        fmt.Sprintf("https://www.youtube.com/watch?v=%s", entry.ID),
+		Duration:   int64(entry.Duration.Seconds()),
+		Author:     entry.Author,
+		Thumbnails: thumbnails,
+	}
+}
+
+// videoInfoFromVideo builds VideoInfo from a fully fetched video
+func videoInfoFromVideo(video *youtube.Video) VideoInfo {
+	// Convert thumbnails
+	thumbnails := make([]Thumbnail, len(video.Thumbnails))
+	for i, thumb := range video.Thumbnails {
+		thumbnails[i] = Thumbnail{
+			URL:    thumb.URL,
+			Width:  thumb.Width,
+			Height: thumb.Height,
+		}
+	}
+
+	// Format publish date as ISO 8601
+	publishDate := ""
+	if !video.PublishDate.IsZero() {
+		publishDate = video.PublishDate.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return VideoInfo{
+		ID:            video.ID,
+		Title:         video.Title,
+		URL:           fmt.Sprintf("https://www.youtube.com/watch?v=%s", video.ID),
+		Duration:      int64(video.Duration.Seconds()),
+		Author:        video.Author,
+		ChannelID:     video.ChannelID,
+		ChannelHandle: video.ChannelHandle,
+		Description:   video.Description,
+		Views:         uint64(video.Views),
+		PublishDate:   publishDate,
+		Thumbnails:    thumbnails,
+	}
 }
 
 // XML structures for parsing YouTube subtitle format
@@ -162,20 +256,30 @@ type text struct {
 func handleSubtitles(params json.RawMessage) (*SubtitleResponse, error) {
 	var req SubtitleRequest
 	if err := json.Unmarshal(params, &req); err != nil {
-		return nil, fmt.Errorf("invalid subtitle request: %w", err)
+		return nil, invalidRequest("invalid subtitle request: %w", err)
 	}
 
 	if req.VideoID == "" {
-		return nil, fmt.Errorf("video_id field is required")
+		return nil, invalidRequest("video_id field is required")
 	}
 
 	fmt.Fprintf(os.Stderr, "DEBUG: Fetching subtitles for video ID: %s\n", req.VideoID)
 
-	client := youtube.Client{}
+	httpClient, err := newYouTubeHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	client := youtube.Client{HTTPClient: httpClient}
 	ctx := context.Background()
 
 	// Get video info
-	video, err := client.GetVideoContext(ctx, req.VideoID)
+	var video *youtube.Video
+	err = withYouTubeRetry(ctx, "video fetch", func() error {
+		var err error
+		video, err = client.GetVideoContext(ctx, req.VideoID)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get video: %w", err)
 	}
@@ -189,13 +293,14 @@ func handleSubtitles(params json.RawMessage) (*SubtitleResponse, error) {
 		}, nil
 	}
 
-	fmt.Fprintf(os.Stderr, "DEBUG: Found %d caption tracks\n", len(video.CaptionTracks))
+	captionTracks := selectCaptionTracks(video.CaptionTracks, req.Languages)
+	fmt.Fprintf(os.Stderr, "DEBUG: Found %d caption tracks, fetching %d\n", len(video.CaptionTracks), len(captionTracks))
 
-	// Extract all available subtitle tracks
-	tracks := make([]SubtitleTrack, 0, len(video.CaptionTracks))
-	for _, caption := range video.CaptionTracks {
+	// Extract the selected subtitle tracks
+	tracks := make([]SubtitleTrack, 0, len(captionTracks))
+	for _, caption := range captionTracks {
 		// Fetch subtitle content from BaseURL
-		content, err := fetchSubtitleContent(caption.BaseURL)
+		content, err := fetchSubtitleContent(httpClient, caption.BaseURL)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "WARNING: Failed to fetch subtitle content for %s (%s): %v\n",
 				caption.LanguageCode, caption.Name.SimpleText, err)
@@ -224,17 +329,127 @@ func handleSubtitles(params json.RawMessage) (*SubtitleResponse, error) {
 	}, nil
 }
 
+// selectCaptionTracks narrows the tracks to download to the requested
+// languages, matched by prefix so "en" includes "en-GB". When none match, the
+// first track is kept so the video still gets a transcript.
+func selectCaptionTracks(tracks []youtube.CaptionTrack, languages []string) []youtube.CaptionTrack {
+	if len(languages) == 0 {
+		return tracks
+	}
+
+	selected := []youtube.CaptionTrack{}
+	for _, track := range tracks {
+		code := strings.ToLower(track.LanguageCode)
+		for _, language := range languages {
+			if strings.HasPrefix(code, strings.ToLower(language)) {
+				selected = append(selected, track)
+				break
+			}
+		}
+	}
+
+	if len(selected) == 0 && len(tracks) > 0 {
+		return tracks[:1]
+	}
+	return selected
+}
+
+// handleAudio returns the audio-only streams for a YouTube video, best first,
+// so a downstream step can send the audio for transcription
+func handleAudio(params json.RawMessage) (*AudioResponse, error) {
+	var req AudioRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, invalidRequest("invalid audio request: %w", err)
+	}
+
+	if req.VideoID == "" {
+		return nil, invalidRequest("video_id field is required")
+	}
+
+	fmt.Fprintf(os.Stderr, "DEBUG: Fetching audio streams for video ID: %s\n", req.VideoID)
+
+	httpClient, err := newYouTubeHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	client := youtube.Client{HTTPClient: httpClient}
+	ctx := context.Background()
+
+	var video *youtube.Video
+	err = withYouTubeRetry(ctx, "video fetch", func() error {
+		var err error
+		video, err = client.GetVideoContext(ctx, req.VideoID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+
+	candidates := selectAudioFormats(video.Formats)
+	if len(candidates) == 0 {
+		return nil, withErrorCode(errCodeNotFound, fmt.Errorf("no audio-only formats available for video %s", req.VideoID))
+	}
+
+	// Stream URLs may need deciphering; skip formats that can't be resolved
+	formats := make([]AudioFormat, 0, len(candidates))
+	for i := range candidates {
+		format := &candidates[i]
+		streamURL, err := client.GetStreamURLContext(ctx, video, format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: Failed to resolve stream URL for itag %d: %v\n", format.ItagNo, err)
+			continue
+		}
+
+		formats = append(formats, AudioFormat{
+			Itag:          format.ItagNo,
+			URL:           streamURL,
+			MimeType:      format.MimeType,
+			Bitrate:       format.Bitrate,
+			AudioQuality:  format.AudioQuality,
+			SampleRate:    format.AudioSampleRate,
+			Channels:      format.AudioChannels,
+			ContentLength: format.ContentLength,
+		})
+	}
+
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("failed to resolve any audio stream URL for video %s", req.VideoID)
+	}
+
+	return &AudioResponse{
+		VideoID:  video.ID,
+		Title:    video.Title,
+		Duration: int64(video.Duration.Seconds()),
+		Best:     formats[0],
+		Formats:  formats,
+	}, nil
+}
+
+// selectAudioFormats returns the audio-only formats ordered by bitrate, highest first
+func selectAudioFormats(formats youtube.FormatList) youtube.FormatList {
+	audio := formats.Select(func(f youtube.Format) bool {
+		return strings.HasPrefix(f.MimeType, "audio/")
+	})
+
+	sort.SliceStable(audio, func(i, j int) bool {
+		return audio[i].Bitrate > audio[j].Bitrate
+	})
+
+	return audio
+}
+
 // fetchSubtitleContent downloads and parses subtitle XML from YouTube
-func fetchSubtitleContent(baseURL string) (string, error) {
+func fetchSubtitleContent(httpClient *http.Client, baseURL string) (string, error) {
 	// Fetch subtitle XML
-	resp, err := http.Get(baseURL)
+	resp, err := httpClient.Get(baseURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch subtitle: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("subtitle fetch returned status %d", resp.StatusCode)
+		return "", withErrorCode(statusErrorCode(resp.StatusCode), fmt.Errorf("subtitle fetch returned status %d", resp.StatusCode))
 	}
 
 	// Read response body
@@ -2,7 +2,10 @@ package main
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
+
+	"github.com/kkdai/youtube/v2"
 )
 
 // TestYouTubeSubtitlesIntegration tests subtitle extraction with a real YouTube video
@@ -99,3 +102,42 @@ func TestSubtitleRequestValidation(t *testing.T) {
 		})
 	}
 }
+
+// TestSelectCaptionTracks tests narrowing caption tracks to the requested languages
+func TestSelectCaptionTracks(t *testing.T) {
+	track := func(code string) youtube.CaptionTrack {
+		return youtube.CaptionTrack{LanguageCode: code}
+	}
+	tracks := []youtube.CaptionTrack{track("de"), track("en"), track("en-GB"), track("fr")}
+
+	testCases := []struct {
+		languages []string
+		expected  []string
+		desc      string
+	}{
+		{languages: nil, expected: []string{"de", "en", "en-GB", "fr"}, desc: "no languages keeps every track"},
+		{languages: []string{"EN"}, expected: []string{"en", "en-GB"}, desc: "prefix match is case-insensitive"},
+		{languages: []string{"fr", "de"}, expected: []string{"de", "fr"}, desc: "track order is kept"},
+		{languages: []string{"ja"}, expected: []string{"de"}, desc: "first track when nothing matches"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			selected := selectCaptionTracks(tracks, tc.languages)
+
+			codes := make([]string, len(selected))
+			for i, caption := range selected {
+				codes[i] = caption.LanguageCode
+			}
+			if strings.Join(codes, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected %v, got %v", tc.expected, codes)
+			}
+
+			t.Logf("✓ Validated: %s", tc.desc)
+		})
+	}
+
+	if selected := selectCaptionTracks(nil, []string{"en"}); len(selected) != 0 {
+		t.Errorf("Expected no tracks for a video without captions, got %d", len(selected))
+	}
+}
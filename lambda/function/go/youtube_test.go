@@ -1,7 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
+
+	"github.com/kkdai/youtube/v2"
 )
 
 // TestNormalizePlaylistURL tests the URL normalization function
@@ -153,3 +160,85 @@ func TestPlaylistIntegration(t *testing.T) {
 		})
 	}
 }
+
+// TestFetchPlaylistVideosFallback tests that entries keep playlist order and fall back
+// to playlist entry data when full video fetches can't complete before the deadline
+func TestFetchPlaylistVideosFallback(t *testing.T) {
+	entries := make([]*youtube.PlaylistEntry, 12)
+	for i := range entries {
+		entries[i] = &youtube.PlaylistEntry{
+			ID:       fmt.Sprintf("video%06d", i),
+			Title:    fmt.Sprintf("Video %d", i),
+			Author:   "Test Channel",
+			Duration: time.Duration(i) * time.Minute,
+		}
+	}
+
+	// An already-expired context and a transport that refuses every request make
+	// each full fetch fail without touching the network
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	httpClient := &http.Client{Transport: failingTransport{}}
+	videos, failures := fetchPlaylistVideos(ctx, httpClient, entries)
+
+	if len(videos) != len(entries) {
+		t.Fatalf("Expected %d videos, got %d", len(entries), len(videos))
+	}
+	if len(failures) != len(entries) {
+		t.Fatalf("Expected %d failures, got %d", len(entries), len(failures))
+	}
+
+	for i, video := range videos {
+		if video.ID != entries[i].ID || video.Title != entries[i].Title {
+			t.Fatalf("Video %d out of order: got %s (%s)", i, video.ID, video.Title)
+		}
+		if video.Duration != int64(i*60) {
+			t.Errorf("Video %d: expected duration %d, got %d", i, i*60, video.Duration)
+		}
+		if video.URL != "https://www.youtube.com/watch?v="+entries[i].ID {
+			t.Errorf("Video %d: unexpected URL %s", i, video.URL)
+		}
+		if failures[i].VideoID != entries[i].ID || failures[i].Reason == "" || failures[i].ErrorCode == "" {
+			t.Errorf("Failure %d: unexpected %+v", i, failures[i])
+		}
+	}
+
+	t.Logf("✓ %d videos returned in playlist order with entry fallback", len(videos))
+}
+
+// failingTransport is an http.RoundTripper that fails every request
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network disabled in test")
+}
+
+// TestSelectAudioFormats tests audio-only filtering and bitrate ordering
+func TestSelectAudioFormats(t *testing.T) {
+	formats := youtube.FormatList{
+		{ItagNo: 18, MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`, Bitrate: 500000, AudioChannels: 2},
+		{ItagNo: 139, MimeType: `audio/mp4; codecs="mp4a.40.5"`, Bitrate: 49000, AudioChannels: 2},
+		{ItagNo: 251, MimeType: `audio/webm; codecs="opus"`, Bitrate: 160000, AudioChannels: 2},
+		{ItagNo: 137, MimeType: `video/mp4; codecs="avc1.640028"`, Bitrate: 4000000},
+		{ItagNo: 140, MimeType: `audio/mp4; codecs="mp4a.40.2"`, Bitrate: 130000, AudioChannels: 2},
+	}
+
+	audio := selectAudioFormats(formats)
+
+	expectedItags := []int{251, 140, 139}
+	if len(audio) != len(expectedItags) {
+		t.Fatalf("Expected %d audio formats, got %d", len(expectedItags), len(audio))
+	}
+	for i, itag := range expectedItags {
+		if audio[i].ItagNo != itag {
+			t.Errorf("Position %d: expected itag %d, got %d", i, itag, audio[i].ItagNo)
+		}
+	}
+
+	if len(selectAudioFormats(youtube.FormatList{formats[0], formats[3]})) != 0 {
+		t.Errorf("Expected no audio-only formats from muxed/video formats")
+	}
+
+	t.Logf("✓ Audio formats ordered: %v", expectedItags)
+}
@@ -9,11 +9,18 @@ import type {
   YouTubeSubtitleResult,
   TMDbSearchPayload,
   LibgenSearchPayload,
+  ContentImportPayload,
+  ContentTreePayload,
+  ContentSearchPayload,
+  ContentMovePayload,
   ScreenshotQueuePayload,
   TSXTranspilePayload,
   TSXTranspileResponse,
   TranscribeAudioPayload,
   TranscribeAudioResult,
+  TimedTranscriptSegment,
+  DeepgramResponse,
+  DeepgramWord,
   ContentItem,
   OpenAIMessage,
   TellerAccountsPayload,
@@ -43,12 +50,41 @@ import {
   fetchTellerBalances,
   fetchTellerTransactions,
 } from "./teller-client.js";
-import { searchLibgen, type BookInfo } from "./libgen-client.js";
+import {
+  searchLibgen,
+  computeLibgenFacets,
+  LIBGEN_SEARCH_DEADLINE_MS,
+  type BookInfo,
+} from "./libgen-client.js";
 import { createClient } from "@deepgram/sdk";
-import { executeGo } from "./go-executor.js";
-import type { SubtitleRequest } from "./go-client.js";
-import { isSubtitleResponse } from "./go-client.js";
+import { executeGo, executeGoBatch, GoMethodError } from "./go-executor.js";
+import { CodedError, errorCodeOf } from "./error-codes.js";
+import type {
+  ContentImportItem,
+  ContentImportRequest,
+  ContentImportSource,
+  ContentTreeRequest,
+  ContentSearchRequest,
+  ContentMoveRequest,
+  GoResponse,
+  SubtitleRequest,
+  SubtitleTrack,
+  VideoFetchError,
+  VideoInfo,
+} from "./go-client.js";
+import {
+  isContentImportResponse,
+  isContentTreeResponse,
+  isContentSearchResponse,
+  isContentMoveResponse,
+  isSubtitleResponse,
+} from "./go-client.js";
+import { getPlaylist } from "./youtube-client.js";
 import { executeYouTubeTranscript } from "./python-client.js";
+import type {
+  TranscriptSegment,
+  YouTubeTranscriptResponse,
+} from "./python-client.js";
 import { streamText, streamObject, convertToModelMessages } from "ai";
 import { openai, createOpenAI } from "@ai-sdk/openai";
 import { z } from "zod";
@@ -598,6 +634,7 @@ export async function handleYouTubePlaylistExtract(
       const result = await processYouTubePlaylistForContent(
         supabase,
         contentItem,
+        payload.withTranscripts === true,
       );
       results.push(result);
     } catch (error: any) {
@@ -609,6 +646,7 @@ export async function handleYouTubePlaylistExtract(
         content_id: contentItem.id,
         success: false,
         error: error.message,
+        error_code: errorCodeOf(error),
         playlist_urls_found: 0,
         videos_created: 0,
       });
@@ -624,6 +662,7 @@ export async function handleYouTubePlaylistExtract(
 export async function processYouTubePlaylistForContent(
   supabase: any,
   contentItem: ContentItem,
+  withTranscripts: boolean = false,
 ) {
   // Extract YouTube playlist URLs from content data
   const playlistUrls = extractYouTubePlaylistUrls(contentItem.data);
@@ -639,7 +678,9 @@ export async function processYouTubePlaylistForContent(
   }
 
   const playlistChildren: ContentItem[] = [];
+  const createdVideos: PlaylistVideoContent[] = [];
   const errors: string[] = [];
+  const videoFailures: any[] = [];
 
   // Process each playlist URL
   for (const playlistUrl of playlistUrls) {
@@ -658,28 +699,29 @@ export async function processYouTubePlaylistForContent(
         throw new Error(`Lambda API error: ${response.status}`);
       }
 
-      const data = (await response.json()) as { videos: any[] };
+      const data = (await response.json()) as {
+        videos: VideoInfo[];
+        failures?: VideoFetchError[];
+      };
       const { videos } = data;
 
+      // Videos that are still imported, but only with the playlist's limited metadata
+      for (const failure of data.failures || []) {
+        videoFailures.push({ ...failure, playlist_url: playlistUrl });
+      }
+
       // Create content items for each video
       for (const video of videos) {
-        const videoData = `${video.title}\n${video.url}`;
+        const videoItem = playlistVideoContent(video, playlistUrl);
         const { data: videoContent, error: createError } = await supabase
           .from("content")
           .insert({
-            type: "text",
-            data: videoData,
+            type: videoItem.type,
+            data: videoItem.data,
             group_id: contentItem.group_id,
             user_id: contentItem.user_id,
             parent_content_id: contentItem.id,
-            metadata: {
-              youtube_video_id: video.id,
-              youtube_title: video.title,
-              youtube_url: video.url,
-              youtube_thumbnail: video.thumbnail,
-              playlist_url: playlistUrl,
-              extracted_from_playlist: true,
-            },
+            metadata: videoItem.metadata,
           })
           .select()
           .single();
@@ -692,6 +734,7 @@ export async function processYouTubePlaylistForContent(
 
         if (videoContent) {
           playlistChildren.push(videoContent as ContentItem);
+          createdVideos.push({ content: videoContent as ContentItem, video });
         }
       }
     } catch (error: any) {
@@ -700,16 +743,257 @@ export async function processYouTubePlaylistForContent(
     }
   }
 
+  // Videos without captions are skipped rather than failing the import
+  const transcripts = withTranscripts
+    ? await createTranscriptsFromSubtitles(supabase, createdVideos)
+    : undefined;
+
   return {
     content_id: contentItem.id,
     success: errors.length === 0,
     playlist_urls_found: playlistUrls.length,
     videos_created: playlistChildren.length,
     playlist_children: playlistChildren,
+    video_failures: videoFailures.length > 0 ? videoFailures : undefined,
+    transcripts_created: transcripts?.created,
+    transcripts_skipped: transcripts?.skipped,
     errors: errors.length > 0 ? errors : undefined,
   };
 }
 
+/**
+ * Content row for a playlist video, shared by playlist extraction and
+ * content-import so both store videos the same way
+ */
+export function playlistVideoContent(
+  video: VideoInfo,
+  playlistUrl: string,
+): ContentImportItem & { type: string } {
+  // Thumbnails are ordered smallest to largest
+  const thumbnail = video.thumbnails?.[video.thumbnails.length - 1]?.url;
+
+  return {
+    type: "text",
+    data: `${video.title}\n${video.url}`,
+    metadata: {
+      youtube_video_id: video.id,
+      youtube_title: video.title,
+      youtube_url: video.url,
+      youtube_thumbnail: thumbnail,
+      playlist_url: playlistUrl,
+      extracted_from_playlist: true,
+    },
+  };
+}
+
+// Captions are fetched through the Go batch protocol: a few videos per
+// process, a few processes at a time, and no new batches once the time budget
+// is spent, so large playlists finish inside the Lambda timeout
+const SUBTITLE_BATCH_SIZE = 10;
+const SUBTITLE_BATCH_CONCURRENCY = 3;
+const SUBTITLE_TIME_BUDGET_MS = 3 * 60 * 1000;
+
+interface PlaylistVideoContent {
+  content: ContentItem;
+  video: VideoInfo;
+}
+
+/**
+ * Fetch captions for playlist videos with batched youtube.subtitles calls and
+ * store each as a child transcript. Videos without captions, failed fetches
+ * and videos left over when the time budget runs out count as skipped.
+ */
+async function createTranscriptsFromSubtitles(
+  supabase: any,
+  videos: PlaylistVideoContent[],
+): Promise<{ created: number; skipped: number }> {
+  const batches: PlaylistVideoContent[][] = [];
+  for (let i = 0; i < videos.length; i += SUBTITLE_BATCH_SIZE) {
+    batches.push(videos.slice(i, i + SUBTITLE_BATCH_SIZE));
+  }
+
+  const deadline = Date.now() + SUBTITLE_TIME_BUDGET_MS;
+  let created = 0;
+  let skipped = 0;
+  let nextBatch = 0;
+
+  const worker = async () => {
+    while (nextBatch < batches.length) {
+      const batch = batches[nextBatch++];
+      const remaining = deadline - Date.now();
+      if (remaining <= 0) {
+        skipped += batch.length;
+        continue;
+      }
+
+      let responses: GoResponse[];
+      try {
+        responses = await executeGoBatch(
+          batch.map(({ video }) => {
+            // English first; other videos still get their first track
+            const request: SubtitleRequest = { video_id: video.id, languages: ["en"] };
+            return { method: "youtube.subtitles", params: request };
+          }),
+          { timeout: remaining },
+        );
+      } catch (error: any) {
+        console.error(`Error fetching subtitles for ${batch.length} videos:`, error);
+        skipped += batch.length;
+        continue;
+      }
+
+      for (let i = 0; i < batch.length; i++) {
+        try {
+          const transcript = await storeSubtitleTranscript(
+            supabase,
+            batch[i].content,
+            batch[i].video,
+            responses[i],
+          );
+          if (transcript) {
+            created++;
+          } else {
+            skipped++;
+          }
+        } catch (error: any) {
+          console.error(`Error storing subtitles for video ${batch[i].video.id}:`, error);
+          skipped++;
+        }
+      }
+    }
+  };
+
+  await Promise.all(
+    Array.from(
+      { length: Math.min(SUBTITLE_BATCH_CONCURRENCY, batches.length) },
+      worker,
+    ),
+  );
+
+  return { created, skipped };
+}
+
+/**
+ * Store a youtube.subtitles response as a child transcript of the video, so
+ * the video is searchable by what is said in it. Prefers English, manual
+ * tracks. Returns null when the video has no captions.
+ */
+async function storeSubtitleTranscript(
+  supabase: any,
+  videoContent: ContentItem,
+  video: VideoInfo,
+  response: GoResponse,
+): Promise<ContentItem | null> {
+  if (!response.success) {
+    throw new GoMethodError(
+      `Subtitle fetch failed: ${response.error}`,
+      response.error_code,
+    );
+  }
+
+  if (!isSubtitleResponse(response.result)) {
+    throw new Error("Invalid subtitle response format");
+  }
+
+  const tracks = response.result.tracks.filter((track) => track.content.trim() !== "");
+  if (tracks.length === 0) {
+    return null;
+  }
+
+  const isEnglish = (code: string) => code.toLowerCase().startsWith("en");
+  const track =
+    tracks.find((t) => isEnglish(t.language_code) && !t.is_automatic) ||
+    tracks.find((t) => isEnglish(t.language_code)) ||
+    tracks.find((t) => !t.is_automatic) ||
+    tracks[0];
+
+  return insertTranscriptContent(supabase, videoContent, video.id, {
+    title: video.title,
+    channel: video.author,
+    transcript: subtitleTrackSegments(track),
+  });
+}
+
+/**
+ * Convert caption track content ("[start seconds] text" per line) into the
+ * transcript segments yt-dlp produces. A segment lasts until the next one
+ * starts; the last has no known duration.
+ */
+function subtitleTrackSegments(track: SubtitleTrack): TranscriptSegment[] {
+  const cues = track.content
+    .split("\n")
+    .map((line) => line.match(/^\[([^\]]*)\]\s*(.*)$/))
+    .filter((match): match is RegExpMatchArray => match !== null && match[2].trim() !== "")
+    .map((match) => ({ offset: Math.round(parseFloat(match[1]) * 1000), text: match[2].trim() }))
+    .filter((cue) => Number.isFinite(cue.offset));
+
+  return cues.map((cue, i) => ({
+    text: cue.text,
+    offset: cue.offset,
+    offsetText: formatOffsetText(cue.offset),
+    duration: i + 1 < cues.length ? Math.max(cues[i + 1].offset - cue.offset, 0) : 0,
+  }));
+}
+
+// Formats milliseconds as H:MM:SS, the way the yt-dlp script writes offsetText
+function formatOffsetText(offset: number): string {
+  const seconds = Math.floor(offset / 1000);
+  const hh = Math.floor(seconds / 3600);
+  const mm = String(Math.floor((seconds % 3600) / 60)).padStart(2, "0");
+  const ss = String(seconds % 60).padStart(2, "0");
+  return `${hh}:${mm}:${ss}`;
+}
+
+/**
+ * Insert a transcript as a child of the video's content item. Transcripts
+ * from yt-dlp and from caption tracks share this one metadata shape.
+ */
+async function insertTranscriptContent(
+  supabase: any,
+  videoContent: ContentItem,
+  videoId: string,
+  transcriptData: YouTubeTranscriptResponse,
+): Promise<ContentItem> {
+  // Convert transcript segments to full text
+  const transcriptText = transcriptData.transcript
+    .map((segment) => segment.text)
+    .join(" ");
+
+  const { data: transcriptContent, error: insertError } = await supabase
+    .from("content")
+    .insert({
+      type: "transcript",
+      data: transcriptText,
+      metadata: {
+        youtube_video_id: videoId,
+        video_title: transcriptData.title,
+        channel: transcriptData.channel,
+        source_content_id: videoContent.id,
+        subtitle_extracted_at: new Date().toISOString(),
+        // Store word-level timing data for future features (video player sync, etc.)
+        segments: transcriptData.transcript,
+        segment_count: transcriptData.transcript.length,
+      },
+      group_id: videoContent.group_id,
+      user_id: videoContent.user_id,
+      parent_content_id: videoContent.id,
+    })
+    .select()
+    .single();
+
+  if (insertError) {
+    throw new Error(
+      `Failed to create transcript content: ${insertError.message}`,
+    );
+  }
+
+  if (!transcriptContent) {
+    throw new Error("No transcript content returned from insert");
+  }
+
+  return transcriptContent as ContentItem;
+}
+
 // =============================================================================
 // TMDB SEARCH
 // =============================================================================
@@ -761,6 +1045,10 @@ export async function handleLibgenSearch(
 ): Promise<ContentResponse> {
   const results = [];
   const autoCreate = payload.autoCreate !== false; // Default to true for backward compatibility
+  // One deadline for the whole batch so a slow mirror can't run past the Lambda timeout
+  const deadline = AbortSignal.timeout(
+    payload.deadlineMs || LIBGEN_SEARCH_DEADLINE_MS,
+  );
 
   for (const contentItem of payload.selectedContent) {
     try {
@@ -772,6 +1060,9 @@ export async function handleLibgenSearch(
         payload.filters,
         payload.maxResults || 10,
         autoCreate,
+        payload.noCache === true,
+        payload.mirrorTimeoutMs,
+        deadline,
       );
       results.push(result);
     } catch (error: any) {
@@ -783,6 +1074,7 @@ export async function handleLibgenSearch(
         content_id: contentItem.id,
         success: false,
         error: error.message,
+        error_code: errorCodeOf(error),
         books_found: 0,
         books_created: 0,
       });
@@ -803,6 +1095,9 @@ async function processLibgenSearchForContent(
   filters?: Record<string, string>,
   maxResults: number = 10,
   autoCreate: boolean = true,
+  noCache: boolean = false,
+  mirrorTimeoutMs?: number,
+  deadline?: AbortSignal,
 ) {
   // Use content data as search query
   const query = contentItem.data.trim();
@@ -818,12 +1113,17 @@ async function processLibgenSearchForContent(
   }
 
   // Search Libgen
-  const books = await searchLibgen({
-    query,
-    search_type: searchType,
-    topics,
-    filters,
-  });
+  const books = await searchLibgen(
+    {
+      query,
+      search_type: searchType,
+      topics,
+      filters,
+      no_cache: noCache,
+      mirror_timeout_ms: mirrorTimeoutMs,
+    },
+    deadline,
+  );
 
   const bookChildren: any[] = [];
   let booksCreated = 0;
@@ -906,6 +1206,7 @@ async function processLibgenSearchForContent(
     books_found: books.length,
     books_created: booksCreated,
     book_children: bookChildren,
+    facets: computeLibgenFacets(books),
   };
 }
 
@@ -935,6 +1236,282 @@ function formatBookData(book: BookInfo): string {
   return parts.join("\n");
 }
 
+// =============================================================================
+// CONTENT ACCESS
+// =============================================================================
+
+/**
+ * Resolve the caller from the Supabase access token sent as the request's
+ * bearer token. User IDs in payloads are never trusted for content methods.
+ */
+async function requireAuthenticatedUser(
+  supabase: any,
+  accessToken?: string,
+): Promise<string> {
+  if (!accessToken) {
+    throw new CodedError("Missing Authorization bearer token", "unauthorized");
+  }
+
+  const {
+    data: { user },
+    error,
+  } = await supabase.auth.getUser(accessToken);
+
+  if (error || !user) {
+    throw new CodedError(
+      `Invalid session: ${error?.message || "user not found"}`,
+      "unauthorized",
+    );
+  }
+
+  return user.id;
+}
+
+/**
+ * Throw unless the authenticated user belongs to the group. Go content methods
+ * connect to Postgres directly, so this check is their only access control.
+ */
+async function requireGroupMember(
+  supabase: any,
+  groupId: string,
+  userId: string,
+): Promise<void> {
+  const { data: membership, error } = await supabase
+    .from("group_memberships")
+    .select("id")
+    .eq("group_id", groupId)
+    .eq("user_id", userId)
+    .maybeSingle();
+
+  if (error) {
+    throw new Error(`Failed to check group membership: ${error.message}`);
+  }
+  if (!membership) {
+    throw new CodedError(
+      `User ${userId} is not a member of group ${groupId}`,
+      "forbidden",
+    );
+  }
+}
+
+// =============================================================================
+// CONTENT IMPORT
+// =============================================================================
+
+/**
+ * Import content into a group with the Go content.import method, which
+ * creates the parent list, items and tags in one transaction and checks the
+ * caller's membership. Playlists are fetched and mapped here so they are
+ * stored like playlist extraction does.
+ */
+export async function handleContentImport(
+  supabase: any,
+  payload: ContentImportPayload,
+  accessToken?: string,
+): Promise<ContentResponse> {
+  const userId = await requireAuthenticatedUser(supabase, accessToken);
+
+  let source: ContentImportSource;
+  let title = payload.title;
+  let failures: VideoFetchError[] | undefined;
+
+  if (payload.source.type === "youtube_playlist") {
+    const { playlist_url, max_videos } = payload.source;
+    const playlist = await getPlaylist(playlist_url, max_videos);
+    source = {
+      type: "items",
+      items: playlist.videos.map((video) =>
+        playlistVideoContent(video, playlist_url),
+      ),
+    };
+    title = title || "YouTube playlist";
+    failures = playlist.failures;
+  } else {
+    source = payload.source;
+  }
+
+  const request: ContentImportRequest = {
+    group_id: payload.group_id,
+    user_id: userId,
+    create_group: payload.create_group,
+    parent_content_id: payload.parent_content_id,
+    title,
+    tags: payload.tags,
+    source,
+  };
+
+  const response = await executeGo(
+    { method: "content.import", params: request },
+    { timeout: 60000 },
+  );
+
+  if (!response.success) {
+    throw new GoMethodError(
+      `Content import failed: ${response.error}`,
+      response.error_code,
+    );
+  }
+
+  if (!isContentImportResponse(response.result)) {
+    throw new Error("Invalid content import response format");
+  }
+
+  return {
+    success: true,
+    data: { ...response.result, failures },
+  };
+}
+
+// =============================================================================
+// CONTENT TREE
+// =============================================================================
+
+/**
+ * Load a group's content hierarchy, or the subtree under root_id, with the
+ * Go content.tree method
+ */
+export async function handleContentTree(
+  supabase: any,
+  payload: ContentTreePayload,
+  accessToken?: string,
+): Promise<ContentResponse> {
+  const userId = await requireAuthenticatedUser(supabase, accessToken);
+
+  if (!payload.group_id) {
+    throw new CodedError("group_id is required", "invalid_request");
+  }
+
+  await requireGroupMember(supabase, payload.group_id, userId);
+
+  const request: ContentTreeRequest = {
+    group_id: payload.group_id,
+    root_id: payload.root_id,
+    max_depth: payload.max_depth,
+    max_nodes: payload.max_nodes,
+  };
+
+  const response = await executeGo({ method: "content.tree", params: request });
+
+  if (!response.success) {
+    throw new GoMethodError(
+      `Content tree failed: ${response.error}`,
+      response.error_code,
+    );
+  }
+
+  if (!isContentTreeResponse(response.result)) {
+    throw new Error("Invalid content tree response format");
+  }
+
+  return { success: true, data: response.result };
+}
+
+// =============================================================================
+// CONTENT SEARCH
+// =============================================================================
+
+/**
+ * Full-text search over a group's content with the Go content.search method
+ */
+export async function handleContentSearch(
+  supabase: any,
+  payload: ContentSearchPayload,
+  accessToken?: string,
+): Promise<ContentResponse> {
+  const userId = await requireAuthenticatedUser(supabase, accessToken);
+
+  if (!payload.group_id) {
+    throw new CodedError("group_id is required", "invalid_request");
+  }
+
+  await requireGroupMember(supabase, payload.group_id, userId);
+
+  const request: ContentSearchRequest = {
+    group_id: payload.group_id,
+    query: payload.query,
+    limit: payload.limit,
+    offset: payload.offset,
+    include_metadata: payload.include_metadata,
+  };
+
+  const response = await executeGo({
+    method: "content.search",
+    params: request,
+  });
+
+  if (!response.success) {
+    throw new GoMethodError(
+      `Content search failed: ${response.error}`,
+      response.error_code,
+    );
+  }
+
+  if (!isContentSearchResponse(response.result)) {
+    throw new Error("Invalid content search response format");
+  }
+
+  return { success: true, data: response.result };
+}
+
+// =============================================================================
+// CONTENT MOVE
+// =============================================================================
+
+/**
+ * Re-parent content with the Go content.move method, which also rewrites its
+ * content_relationships row. A null new_parent_id moves it to the top level.
+ */
+export async function handleContentMove(
+  supabase: any,
+  payload: ContentMovePayload,
+  accessToken?: string,
+): Promise<ContentResponse> {
+  const userId = await requireAuthenticatedUser(supabase, accessToken);
+
+  if (!payload.content_id) {
+    throw new CodedError("content_id is required", "invalid_request");
+  }
+
+  // The new parent must be in the same group, which content.move enforces
+  const { data: content, error } = await supabase
+    .from("content")
+    .select("group_id")
+    .eq("id", payload.content_id)
+    .maybeSingle();
+
+  if (error) {
+    throw new Error(`Failed to load content: ${error.message}`);
+  }
+  if (!content) {
+    throw new CodedError(
+      `Content ${payload.content_id} not found`,
+      "not_found",
+    );
+  }
+
+  await requireGroupMember(supabase, content.group_id, userId);
+
+  const request: ContentMoveRequest = {
+    content_id: payload.content_id,
+    new_parent_id: payload.new_parent_id ?? null,
+  };
+
+  const response = await executeGo({ method: "content.move", params: request });
+
+  if (!response.success) {
+    throw new GoMethodError(
+      `Content move failed: ${response.error}`,
+      response.error_code,
+    );
+  }
+
+  if (!isContentMoveResponse(response.result)) {
+    throw new Error("Invalid content move response format");
+  }
+
+  return { success: true, data: response.result };
+}
+
 // =============================================================================
 // SCREENSHOT GENERATION
 // =============================================================================
@@ -1135,6 +1712,8 @@ export async function handleTSXTranspile(
 /**
  * Handle audio transcription using Deepgram API
  * Creates a child content item with type='transcript' containing the Deepgram response
+ * and word-timed segments, and returns the transcript as a caption track for
+ * videos that have no YouTube captions
  */
 export async function handleTranscribeAudio(
   supabase: any,
@@ -1220,6 +1799,8 @@ async function processTranscribeAudioForContent(
 
   console.log(`Transcription completed for content ${contentItem.id}`);
 
+  const segments = deepgramTranscriptSegments(result as DeepgramResponse);
+
   // Create transcript content as child
   const { data: transcriptContent, error: insertError } = await supabase
     .from("content")
@@ -1231,6 +1812,8 @@ async function processTranscribeAudioForContent(
         source_audio_url: audioUrl,
         source_content_id: contentItem.id,
         transcribed_at: new Date().toISOString(),
+        segments,
+        segment_count: segments.length,
       },
       group_id: contentItem.group_id,
       user_id: contentItem.user_id,
@@ -1253,9 +1836,69 @@ async function processTranscribeAudioForContent(
     content_id: contentItem.id,
     success: true,
     transcript_content_id: transcriptContent.id,
+    track: {
+      // nova-2 transcribes English unless a language is requested
+      language_code: "en",
+      name: "Deepgram transcript",
+      base_url: audioUrl,
+      content: segments
+        .map((segment) => `[${(segment.offset / 1000).toFixed(2)}] ${segment.text}`)
+        .join("\n"),
+      is_automatic: true,
+    },
   };
 }
 
+/**
+ * Convert a Deepgram response into transcript segments, one per utterance
+ * with its word timings. Without utterances the first channel is a single
+ * segment.
+ */
+export function deepgramTranscriptSegments(
+  response: DeepgramResponse,
+): TimedTranscriptSegment[] {
+  const toMs = (seconds: number) => Math.round(seconds * 1000);
+  const segment = (
+    text: string,
+    start: number,
+    end: number,
+    words: DeepgramWord[],
+    speaker?: number,
+  ): TimedTranscriptSegment => ({
+    text,
+    offset: toMs(start),
+    offsetText: formatOffsetText(toMs(start)),
+    duration: Math.max(toMs(end) - toMs(start), 0),
+    speaker,
+    words: words.map((word) => ({
+      word: word.punctuated_word || word.word,
+      offset: toMs(word.start),
+      duration: Math.max(toMs(word.end) - toMs(word.start), 0),
+    })),
+  });
+
+  const utterances = response.results.utterances || [];
+  if (utterances.length > 0) {
+    return utterances.map((utterance) =>
+      segment(utterance.transcript, utterance.start, utterance.end, utterance.words, utterance.speaker),
+    );
+  }
+
+  const alternative = response.results.channels[0]?.alternatives[0];
+  if (!alternative || alternative.transcript.trim() === "") {
+    return [];
+  }
+  const words = alternative.words || [];
+  return [
+    segment(
+      alternative.transcript,
+      words[0]?.start ?? 0,
+      words[words.length - 1]?.end ?? 0,
+      words,
+    ),
+  ];
+}
+
 // =============================================================================
 // YOUTUBE SUBTITLE EXTRACTION
 // =============================================================================
@@ -1328,43 +1971,13 @@ async function processYouTubeSubtitlesForContent(
   );
   console.log(`Transcript has ${transcriptData.transcript.length} segments`);
 
-  // Convert transcript segments to full text
-  const transcriptText = transcriptData.transcript
-    .map((segment) => segment.text)
-    .join(" ");
-
   // Create transcript content as child
-  const { data: transcriptContent, error: insertError } = await supabase
-    .from("content")
-    .insert({
-      type: "transcript",
-      data: transcriptText,
-      metadata: {
-        youtube_video_id: videoId,
-        video_title: transcriptData.title,
-        channel: transcriptData.channel,
-        source_content_id: contentItem.id,
-        subtitle_extracted_at: new Date().toISOString(),
-        // Store word-level timing data for future features (video player sync, etc.)
-        segments: transcriptData.transcript,
-        segment_count: transcriptData.transcript.length,
-      },
-      group_id: contentItem.group_id,
-      user_id: contentItem.user_id,
-      parent_content_id: contentItem.id,
-    })
-    .select()
-    .single();
-
-  if (insertError) {
-    throw new Error(
-      `Failed to create transcript content: ${insertError.message}`,
-    );
-  }
-
-  if (!transcriptContent) {
-    throw new Error("No transcript content returned from insert");
-  }
+  const transcriptContent = await insertTranscriptContent(
+    supabase,
+    contentItem,
+    videoId,
+    transcriptData,
+  );
 
   console.log(
     `Created transcript content ${transcriptContent.id} for video "${transcriptData.title}"`,
@@ -0,0 +1,50 @@
+import type { GoErrorCode } from './go-client.js';
+
+/**
+ * Go handler error codes, plus the caller-identity failures only the Lambda
+ * can raise: a missing or invalid session, or a user outside the group
+ */
+export type ErrorCode = GoErrorCode | 'unauthorized' | 'forbidden';
+
+/**
+ * Error carrying one of the Go handler's error codes, so failures raised in
+ * TypeScript (e.g. Libgen scraping) are classified the same way as Go methods
+ */
+export class CodedError extends Error {
+	readonly code?: ErrorCode;
+
+	constructor(message: string, code?: ErrorCode) {
+		super(message);
+		this.name = 'CodedError';
+		this.code = code;
+	}
+}
+
+/**
+ * Error code of a thrown value, if it carries one
+ */
+export function errorCodeOf(error: unknown): ErrorCode | undefined {
+	return error instanceof CodedError ? error.code : undefined;
+}
+
+/**
+ * HTTP status to answer with for an error code; uncoded failures are a 500
+ */
+export function errorCodeStatus(code?: ErrorCode): number {
+	switch (code) {
+		case 'invalid_request':
+			return 400;
+		case 'unauthorized':
+			return 401;
+		case 'forbidden':
+			return 403;
+		case 'not_found':
+			return 404;
+		case 'rate_limited':
+			return 429;
+		case 'timeout':
+			return 504;
+		default:
+			return 500;
+	}
+}
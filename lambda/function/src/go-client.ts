@@ -10,11 +10,14 @@ export const RequestSchema = z.object({
 export const ResponseSchema = z.object({
 	success: z.boolean(),
 	result: z.unknown().optional(),
-	error: z.string().optional()
+	error: z.string().optional(),
+	error_code: z.string().optional()
 });
 
 export const PlaylistRequestSchema = z.object({
-	url: z.string().url()
+	url: z.string().url(),
+	timeout_seconds: z.number().optional(),
+	max_videos: z.number().optional()
 });
 
 export const ThumbnailSchema = z.object({
@@ -37,12 +40,21 @@ export const VideoInfoSchema = z.object({
 	thumbnails: z.array(ThumbnailSchema)
 });
 
+export const VideoFetchErrorSchema = z.object({
+	video_id: z.string(),
+	title: z.string(),
+	reason: z.string(),
+	error_code: z.string()
+});
+
 export const PlaylistResponseSchema = z.object({
-	videos: z.array(VideoInfoSchema)
+	videos: z.array(VideoInfoSchema),
+	failures: z.array(VideoFetchErrorSchema)
 });
 
 export const SubtitleRequestSchema = z.object({
-	video_id: z.string()
+	video_id: z.string(),
+	languages: z.array(z.string()).optional()
 });
 
 export const SubtitleTrackSchema = z.object({
@@ -58,6 +70,159 @@ export const SubtitleResponseSchema = z.object({
 	tracks: z.array(SubtitleTrackSchema)
 });
 
+export const LinkMetadataRequestSchema = z.object({
+	url: z.string().url(),
+	timeout_seconds: z.number().optional()
+});
+
+export const LinkMetadataResponseSchema = z.object({
+	url: z.string(),
+	title: z.string(),
+	description: z.string(),
+	image: z.string(),
+	site_name: z.string(),
+	favicon: z.string()
+});
+
+export const RSSFetchRequestSchema = z.object({
+	url: z.string().url(),
+	since: z.string().optional(),
+	timeout_seconds: z.number().optional()
+});
+
+export const FeedEntrySchema = z.object({
+	title: z.string(),
+	url: z.string(),
+	author: z.string(),
+	published: z.string(),
+	summary: z.string()
+});
+
+export const FeedResponseSchema = z.object({
+	url: z.string(),
+	title: z.string(),
+	link: z.string(),
+	format: z.enum(['rss', 'atom']),
+	description: z.string(),
+	entries: z.array(FeedEntrySchema)
+});
+
+export const ContentSearchRequestSchema = z.object({
+	group_id: z.string().uuid(),
+	query: z.string().min(1),
+	limit: z.number().optional(),
+	offset: z.number().optional(),
+	include_metadata: z.boolean().optional()
+});
+
+export const ContentSearchResultSchema = z.object({
+	id: z.string(),
+	type: z.string(),
+	data: z.string(),
+	parent_content_id: z.string().optional(),
+	metadata: z.unknown().optional(),
+	created_at: z.string(),
+	rank: z.number(),
+	snippet: z.string()
+});
+
+export const ContentSearchResponseSchema = z.object({
+	query: z.string(),
+	results: z.array(ContentSearchResultSchema)
+});
+
+export const ContentMoveRequestSchema = z.object({
+	content_id: z.string().uuid(),
+	new_parent_id: z.string().uuid().nullable()
+});
+
+export const ContentMoveResponseSchema = z.object({
+	content_id: z.string(),
+	parent_content_id: z.string().optional(),
+	previous_parent_id: z.string().optional()
+});
+
+export const ContentTreeRequestSchema = z.object({
+	group_id: z.string().uuid(),
+	root_id: z.string().uuid().optional(),
+	max_depth: z.number().optional(),
+	max_nodes: z.number().optional()
+});
+
+export const ContentTreeNodeSchema: z.ZodType<ContentTreeNode> = z.lazy(() =>
+	z.object({
+		id: z.string(),
+		type: z.string(),
+		data: z.string(),
+		metadata: z.unknown().optional(),
+		created_at: z.string(),
+		child_count: z.number(),
+		truncated_count: z.number(),
+		children: z.array(ContentTreeNodeSchema)
+	})
+);
+
+export const ContentTreeResponseSchema = z.object({
+	group_id: z.string(),
+	roots: z.array(ContentTreeNodeSchema),
+	node_count: z.number(),
+	truncated: z.boolean()
+});
+
+export const ContentImportItemSchema = z.object({
+	type: z.string().optional(),
+	data: z.string(),
+	metadata: z.unknown().optional()
+});
+
+export const ContentImportSourceSchema = z.object({
+	type: z.enum(['urls', 'rss', 'items']),
+	urls: z.array(z.string()).optional(),
+	feed_url: z.string().optional(),
+	since: z.string().optional(),
+	items: z.array(ContentImportItemSchema).optional()
+});
+
+export const ContentImportRequestSchema = z.object({
+	group_id: z.string().uuid().optional(),
+	user_id: z.string().uuid(),
+	create_group: z.boolean().optional(),
+	parent_content_id: z.string().uuid().optional(),
+	title: z.string().optional(),
+	tags: z.array(z.string()).optional(),
+	source: ContentImportSourceSchema
+});
+
+export const ContentImportResponseSchema = z.object({
+	group_id: z.string(),
+	parent_content_id: z.string(),
+	content_ids: z.array(z.string()),
+	skipped: z.array(z.string())
+});
+
+export const AudioRequestSchema = z.object({
+	video_id: z.string()
+});
+
+export const AudioFormatSchema = z.object({
+	itag: z.number(),
+	url: z.string(),
+	mime_type: z.string(),
+	bitrate: z.number(),
+	audio_quality: z.string(),
+	sample_rate: z.string(),
+	channels: z.number(),
+	content_length: z.number()
+});
+
+export const AudioResponseSchema = z.object({
+	video_id: z.string(),
+	title: z.string(),
+	duration: z.number(),
+	best: AudioFormatSchema,
+	formats: z.array(AudioFormatSchema)
+});
+
 // TypeScript types - mirrors Go structs with snake_case JSON fields
 
 export interface GoRequest {
@@ -69,10 +234,22 @@ export interface GoResponse {
 	success: boolean;
 	result?: unknown;
 	error?: string;
+	error_code?: GoErrorCode;
 }
 
+// Machine-readable error codes set by the Go binary alongside the error message
+export type GoErrorCode =
+	| 'invalid_request'
+	| 'not_found'
+	| 'rate_limited'
+	| 'timeout'
+	| 'upstream_error'
+	| 'internal_error';
+
 export interface PlaylistRequest {
 	url: string;
+	timeout_seconds?: number;
+	max_videos?: number;
 }
 
 export interface Thumbnail {
@@ -95,12 +272,21 @@ export interface VideoInfo {
 	thumbnails: Thumbnail[];
 }
 
+export interface VideoFetchError {
+	video_id: string;
+	title: string;
+	reason: string;
+	error_code: GoErrorCode;
+}
+
 export interface PlaylistResponse {
 	videos: VideoInfo[];
+	failures: VideoFetchError[];
 }
 
 export interface SubtitleRequest {
 	video_id: string;
+	languages?: string[]; // Only download these languages (prefix match); the first track if none match
 }
 
 export interface SubtitleTrack {
@@ -116,6 +302,157 @@ export interface SubtitleResponse {
 	tracks: SubtitleTrack[];
 }
 
+export interface LinkMetadataRequest {
+	url: string;
+	timeout_seconds?: number;
+}
+
+export interface LinkMetadataResponse {
+	url: string;
+	title: string;
+	description: string;
+	image: string;
+	site_name: string;
+	favicon: string;
+}
+
+export interface RSSFetchRequest {
+	url: string;
+	since?: string;
+	timeout_seconds?: number;
+}
+
+export interface FeedEntry {
+	title: string;
+	url: string;
+	author: string;
+	published: string;
+	summary: string;
+}
+
+export interface FeedResponse {
+	url: string;
+	title: string;
+	link: string;
+	format: 'rss' | 'atom';
+	description: string;
+	entries: FeedEntry[];
+}
+
+export interface ContentSearchRequest {
+	group_id: string;
+	query: string;
+	limit?: number;
+	offset?: number;
+	include_metadata?: boolean;
+}
+
+export interface ContentSearchResult {
+	id: string;
+	type: string;
+	data: string;
+	parent_content_id?: string;
+	metadata?: unknown;
+	created_at: string;
+	rank: number;
+	snippet: string;
+}
+
+export interface ContentSearchResponse {
+	query: string;
+	results: ContentSearchResult[];
+}
+
+export interface ContentMoveRequest {
+	content_id: string;
+	new_parent_id: string | null;
+}
+
+export interface ContentMoveResponse {
+	content_id: string;
+	parent_content_id?: string;
+	previous_parent_id?: string;
+}
+
+export interface ContentTreeRequest {
+	group_id: string;
+	root_id?: string;
+	max_depth?: number;
+	max_nodes?: number;
+}
+
+export interface ContentTreeNode {
+	id: string;
+	type: string;
+	data: string;
+	metadata?: unknown;
+	created_at: string;
+	child_count: number;
+	truncated_count: number;
+	children: ContentTreeNode[];
+}
+
+export interface ContentTreeResponse {
+	group_id: string;
+	roots: ContentTreeNode[];
+	node_count: number;
+	truncated: boolean;
+}
+
+export interface ContentImportItem {
+	type?: string;
+	data: string;
+	metadata?: unknown;
+}
+
+export interface ContentImportSource {
+	type: 'urls' | 'rss' | 'items';
+	urls?: string[];
+	feed_url?: string; // For rss: entry URLs become text items tagged rss
+	since?: string; // For rss: only entries published after this date
+	items?: ContentImportItem[];
+}
+
+export interface ContentImportRequest {
+	group_id?: string; // Required unless create_group is set
+	user_id: string;
+	create_group?: boolean; // Import into a new group named like the list
+	parent_content_id?: string;
+	title?: string;
+	tags?: string[];
+	source: ContentImportSource;
+}
+
+export interface ContentImportResponse {
+	group_id: string;
+	parent_content_id: string;
+	content_ids: string[];
+	skipped: string[];
+}
+
+export interface AudioRequest {
+	video_id: string;
+}
+
+export interface AudioFormat {
+	itag: number;
+	url: string;
+	mime_type: string;
+	bitrate: number;
+	audio_quality: string;
+	sample_rate: string;
+	channels: number;
+	content_length: number;
+}
+
+export interface AudioResponse {
+	video_id: string;
+	title: string;
+	duration: number;
+	best: AudioFormat;
+	formats: AudioFormat[];
+}
+
 // Type guards
 
 export function isGoResponse(data: unknown): data is GoResponse {
@@ -132,3 +469,38 @@ export function isSubtitleResponse(data: unknown): data is SubtitleResponse {
 	const result = SubtitleResponseSchema.safeParse(data);
 	return result.success;
 }
+
+export function isLinkMetadataResponse(data: unknown): data is LinkMetadataResponse {
+	const result = LinkMetadataResponseSchema.safeParse(data);
+	return result.success;
+}
+
+export function isFeedResponse(data: unknown): data is FeedResponse {
+	const result = FeedResponseSchema.safeParse(data);
+	return result.success;
+}
+
+export function isContentSearchResponse(data: unknown): data is ContentSearchResponse {
+	const result = ContentSearchResponseSchema.safeParse(data);
+	return result.success;
+}
+
+export function isContentMoveResponse(data: unknown): data is ContentMoveResponse {
+	const result = ContentMoveResponseSchema.safeParse(data);
+	return result.success;
+}
+
+export function isContentTreeResponse(data: unknown): data is ContentTreeResponse {
+	const result = ContentTreeResponseSchema.safeParse(data);
+	return result.success;
+}
+
+export function isContentImportResponse(data: unknown): data is ContentImportResponse {
+	const result = ContentImportResponseSchema.safeParse(data);
+	return result.success;
+}
+
+export function isAudioResponse(data: unknown): data is AudioResponse {
+	const result = AudioResponseSchema.safeParse(data);
+	return result.success;
+}
@@ -1,12 +1,27 @@
 import { spawn } from 'child_process';
-import type { GoRequest, GoResponse } from './go-client.js';
+import type { GoErrorCode, GoRequest, GoResponse } from './go-client.js';
 import { isGoResponse } from './go-client.js';
+import { CodedError } from './error-codes.js';
+
+/**
+ * Error for a failed Go method call, carrying the Go error code so callers
+ * can tell a bad request from an upstream failure
+ */
+export class GoMethodError extends CodedError {
+	constructor(message: string, code?: GoErrorCode) {
+		super(message, code);
+		this.name = 'GoMethodError';
+	}
+}
 
 export interface GoExecutorOptions {
 	binaryPath?: string;
-	timeout?: number; // milliseconds
+	timeout?: number; // milliseconds; for batches, defaults to the single-request timeout per item
 }
 
+// Kill timeout for one request; the Go side's own defaults (e.g. 25s for playlists) fit inside it
+const DEFAULT_TIMEOUT_MS = 30000;
+
 /**
  * Execute Go binary with JSON request via stdin and parse JSON response from stdout
  */
@@ -14,9 +29,66 @@ export async function executeGo(
 	request: GoRequest,
 	options: GoExecutorOptions = {}
 ): Promise<GoResponse> {
+	const stdoutData = await runGoBinary(JSON.stringify(request), options);
+
+	let response: unknown;
+	try {
+		response = JSON.parse(stdoutData.trim());
+	} catch (error) {
+		throw new Error(`Failed to parse Go binary output: ${error}. stdout: ${stdoutData}`);
+	}
+
+	if (!isGoResponse(response)) {
+		throw new Error(`Invalid response format from Go binary: ${stdoutData}`);
+	}
+
+	return response;
+}
+
+/**
+ * Execute several requests in one Go binary invocation.
+ * Responses are returned in request order; each carries its own success/error,
+ * so one failing item does not fail the batch.
+ * The Go side runs items one after another, so unless options.timeout is set
+ * the kill timeout scales with the number of requests.
+ */
+export async function executeGoBatch(
+	requests: GoRequest[],
+	options: GoExecutorOptions = {}
+): Promise<GoResponse[]> {
+	if (requests.length === 0) {
+		return [];
+	}
+
+	const timeout = options.timeout ?? DEFAULT_TIMEOUT_MS * requests.length;
+	const stdoutData = await runGoBinary(JSON.stringify(requests), { ...options, timeout });
+
+	let responses: unknown;
+	try {
+		responses = JSON.parse(stdoutData.trim());
+	} catch (error) {
+		throw new Error(`Failed to parse Go binary output: ${error}. stdout: ${stdoutData}`);
+	}
+
+	// A malformed batch is answered with a single error response
+	if (isGoResponse(responses)) {
+		throw new GoMethodError(`Go batch request failed: ${responses.error}`, responses.error_code);
+	}
+
+	if (!Array.isArray(responses) || responses.length !== requests.length || !responses.every(isGoResponse)) {
+		throw new Error(`Invalid batch response format from Go binary: ${stdoutData}`);
+	}
+
+	return responses;
+}
+
+/**
+ * Spawn the Go binary, write a single line of input to stdin and collect stdout
+ */
+function runGoBinary(input: string, options: GoExecutorOptions): Promise<string> {
 	const {
 		binaryPath = '/usr/local/bin/youtube-handler',
-		timeout = 30000 // 30 seconds default
+		timeout = DEFAULT_TIMEOUT_MS
 	} = options;
 
 	return new Promise((resolve, reject) => {
@@ -52,19 +124,7 @@ export async function executeGo(
 				return;
 			}
 
-			// Parse JSON response
-			try {
-				const response = JSON.parse(stdoutData.trim());
-
-				if (!isGoResponse(response)) {
-					reject(new Error(`Invalid response format from Go binary: ${stdoutData}`));
-					return;
-				}
-
-				resolve(response);
-			} catch (error) {
-				reject(new Error(`Failed to parse Go binary output: ${error}. stdout: ${stdoutData}`));
-			}
+			resolve(stdoutData);
 		});
 
 		// Handle spawn errors
@@ -75,8 +135,7 @@ export async function executeGo(
 
 		// Send request to stdin
 		try {
-			const requestJSON = JSON.stringify(request);
-			child.stdin.write(requestJSON + '\n');
+			child.stdin.write(input + '\n');
 			child.stdin.end();
 		} catch (error) {
 			clearTimeout(timeoutId);
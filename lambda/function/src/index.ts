@@ -2,7 +2,8 @@ import type { APIGatewayProxyHandlerV2, APIGatewayProxyEventV2, APIGatewayProxyR
 import { convertToModelMessages } from 'ai';
 import { SessionManager } from './session-manager.js';
 import { executeClaudeCode } from './claude-executor.js';
-import { getPlaylistVideos } from './youtube-client.js';
+import { getPlaylist } from './youtube-client.js';
+import { errorCodeOf, errorCodeStatus } from './error-codes.js';
 import { getSupabaseClient } from './supabase-client.js';
 import { JobManager } from './job-manager.js';
 import {
@@ -16,6 +17,10 @@ import {
 	handleYouTubeSubtitleExtract,
 	handleTMDbSearch,
 	handleLibgenSearch,
+	handleContentImport,
+	handleContentTree,
+	handleContentSearch,
+	handleContentMove,
 	handleScreenshotQueue,
 	handleTSXTranspile,
 	handleTranscribeAudio,
@@ -83,7 +88,7 @@ interface ClaudeCodeResponse {
 const corsHeaders = {
 	'Access-Control-Allow-Origin': '*',
 	'Access-Control-Allow-Methods': 'GET, POST, OPTIONS',
-	'Access-Control-Allow-Headers': 'Content-Type',
+	'Access-Control-Allow-Headers': 'Content-Type, Authorization',
 	'Access-Control-Max-Age': '86400',
 };
 
@@ -544,10 +549,11 @@ export const handler: APIGatewayProxyHandlerV2 = async (
 ): Promise<APIGatewayProxyResultV2 | any> => {
 	// Direct stderr write - should bypass all buffering
 	process.stderr.write('=== HANDLER STARTED ===\n');
-	process.stderr.write(`Event: ${JSON.stringify(event)}\n`);
+	const loggedEvent = redactAuthorization(event);
+	process.stderr.write(`Event: ${JSON.stringify(loggedEvent)}\n`);
 
 	console.log('LAMBDA_START: Handler invoked');
-	console.log('LAMBDA_EVENT:', JSON.stringify(event, null, 2));
+	console.log('LAMBDA_EVENT:', JSON.stringify(loggedEvent, null, 2));
 
 	// Detect invocation source
 	const isSQSEvent = event.Records && Array.isArray(event.Records) && event.Records[0]?.eventSource === 'aws:sqs';
@@ -647,7 +653,7 @@ export const handler: APIGatewayProxyHandlerV2 = async (
 		}
 
 		if (path === '/content' || path === '/content/') {
-			result = await handleContentRequest(body as ContentRequest);
+			result = await handleContentRequest(body as ContentRequest, bearerToken(event));
 			await flushLogs();
 			return result;
 		}
@@ -707,7 +713,7 @@ async function handleYouTubePlaylistRequest(body: { url?: string }): Promise<API
 	}
 
 	try {
-		const videos = await getPlaylistVideos(url);
+		const { videos, failures } = await getPlaylist(url);
 
 		return {
 			statusCode: 200,
@@ -717,21 +723,24 @@ async function handleYouTubePlaylistRequest(body: { url?: string }): Promise<API
 			},
 			body: JSON.stringify({
 				success: true,
-				videos
+				videos,
+				failures
 			})
 		};
 	} catch (error) {
 		console.error('YouTube playlist error:', error);
+		const errorCode = errorCodeOf(error);
 
 		return {
-			statusCode: 500,
+			statusCode: errorCodeStatus(errorCode),
 			headers: {
 				'Content-Type': 'application/json',
 				...corsHeaders
 			},
 			body: JSON.stringify({
 				success: false,
-				error: error instanceof Error ? error.message : 'Failed to fetch playlist'
+				error: error instanceof Error ? error.message : 'Failed to fetch playlist',
+				error_code: errorCode
 			})
 		};
 	}
@@ -1053,7 +1062,30 @@ async function handleCancelJob(jobManager: JobManager, payload: any): Promise<AP
 	}
 }
 
-async function handleContentRequest(request: ContentRequest): Promise<APIGatewayProxyResultV2> {
+/**
+ * Copy of an event safe to log: the bearer token is replaced
+ */
+function redactAuthorization(event: any): any {
+	if (!event?.headers?.authorization && !event?.headers?.Authorization) {
+		return event;
+	}
+	const headers = { ...event.headers };
+	for (const name of ['authorization', 'Authorization']) {
+		if (headers[name]) headers[name] = '[redacted]';
+	}
+	return { ...event, headers };
+}
+
+/**
+ * Supabase access token from the Authorization header, if the caller sent one
+ */
+function bearerToken(event: APIGatewayProxyEventV2): string | undefined {
+	const header = event.headers?.authorization || event.headers?.Authorization;
+	const match = header?.match(/^Bearer\s+(.+)$/i);
+	return match ? match[1] : undefined;
+}
+
+async function handleContentRequest(request: ContentRequest, accessToken?: string): Promise<APIGatewayProxyResultV2> {
 	let { action, payload } = request;
 
 	console.log('[HANDLER] Received request:', JSON.stringify(request, null, 2));
@@ -1597,6 +1629,22 @@ Current message:
 				result = await handleBlockNoteExport(supabase, payload);
 				break;
 
+			case 'content-import':
+				result = await handleContentImport(supabase, payload, accessToken);
+				break;
+
+			case 'content-tree':
+				result = await handleContentTree(supabase, payload, accessToken);
+				break;
+
+			case 'content-search':
+				result = await handleContentSearch(supabase, payload, accessToken);
+				break;
+
+			case 'content-move':
+				result = await handleContentMove(supabase, payload, accessToken);
+				break;
+
 			case 'get-job':
 				// Get specific job status
 				return await handleGetJobStatus(jobManager, payload);
@@ -1653,16 +1701,18 @@ Current message:
 		};
 	} catch (error) {
 		console.error('Content request error:', error);
+		const errorCode = errorCodeOf(error);
 
 		return {
-			statusCode: 500,
+			statusCode: errorCodeStatus(errorCode),
 			headers: {
 				'Content-Type': 'application/json',
 				...corsHeaders
 			},
 			body: JSON.stringify({
 				success: false,
-				error: error instanceof Error ? error.message : 'Internal server error'
+				error: error instanceof Error ? error.message : 'Internal server error',
+				error_code: errorCode
 			})
 		};
 	}
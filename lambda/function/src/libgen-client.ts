@@ -1,4 +1,7 @@
 import { searchLibgen as searchLibgenDirect, type BookInfo as LibgenBookInfo } from './libgen-search.js';
+import { CodedError, errorCodeOf } from './error-codes.js';
+
+export { computeLibgenFacets, LIBGEN_SEARCH_DEADLINE_MS, type LibgenFacets } from './libgen-search.js';
 
 /**
  * Libgen search request parameters
@@ -7,7 +10,9 @@ export interface LibgenSearchRequest {
 	query: string;
 	search_type?: 'default' | 'title' | 'author';
 	topics?: string[];
-	filters?: Record<string, string>;
+	filters?: Record<string, string>; // year, extension, language; year_min, year_max, size_max (e.g. "20" MB or "500 KB") are applied locally
+	no_cache?: boolean; // Skip the result cache and always scrape
+	mirror_timeout_ms?: number; // Give up on an unresponsive mirror after this long
 }
 
 /**
@@ -20,15 +25,18 @@ export type BookInfo = LibgenBookInfo;
  * Search for books on Libgen
  * Now uses native TypeScript implementation instead of Go binary
  */
-export async function searchLibgen(request: LibgenSearchRequest): Promise<BookInfo[]> {
+export async function searchLibgen(request: LibgenSearchRequest, deadline?: AbortSignal): Promise<BookInfo[]> {
 	console.log('Searching Libgen with TypeScript implementation:', request);
 
 	try {
-		const books = await searchLibgenDirect(request);
+		const books = await searchLibgenDirect(request, deadline);
 		console.log(`Libgen search completed: found ${books.length} books`);
 		return books;
 	} catch (error) {
 		console.error('Libgen search failed:', error);
-		throw new Error(`Libgen search failed: ${error instanceof Error ? error.message : 'Unknown error'}`);
+		throw new CodedError(
+			`Libgen search failed: ${error instanceof Error ? error.message : 'Unknown error'}`,
+			errorCodeOf(error)
+		);
 	}
 }
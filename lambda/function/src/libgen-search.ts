@@ -1,4 +1,5 @@
 import * as cheerio from 'cheerio';
+import { CodedError } from './error-codes.js';
 
 /**
  * Libgen search request parameters
@@ -7,7 +8,9 @@ export interface LibgenSearchRequest {
 	query: string;
 	search_type?: 'default' | 'title' | 'author';
 	topics?: string[];
-	filters?: Record<string, string>;
+	filters?: Record<string, string>; // year, extension, language; year_min, year_max, size_max (e.g. "20" MB or "500 KB") are applied locally
+	no_cache?: boolean; // Skip the result cache and always scrape
+	mirror_timeout_ms?: number; // Give up on an unresponsive mirror after this long
 }
 
 /**
@@ -27,10 +30,88 @@ export interface BookInfo {
 	mirrors: string[];
 }
 
+/**
+ * Result counts by extension, language and publication decade, for filter UIs
+ */
+export interface LibgenFacets {
+	extension: Record<string, number>;
+	language: Record<string, number>;
+	decade: Record<string, number>; // e.g. "1990s"; books without a parseable year are under "unknown"
+}
+
 const LIBGEN_MIRROR = 'https://libgen.li';
 const SEARCH_PATH = '/index.php';
 const USER_AGENT = 'Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36';
 
+// A stalled mirror should fail fast, and a whole batch of searches must finish
+// well inside the 5 minute Lambda timeout so partial results can be returned
+const MIRROR_TIMEOUT_MS = 20 * 1000;
+export const LIBGEN_SEARCH_DEADLINE_MS = 4 * 60 * 1000;
+
+// Scrapes are slow and mirrors are fragile, so results are cached per warm Lambda container
+const CACHE_TTL_MS = 10 * 60 * 1000;
+const CACHE_MAX_ENTRIES = 100;
+
+interface CacheEntry {
+	books: BookInfo[];
+	expiresAt: number;
+}
+
+const searchCache = new Map<string, CacheEntry>();
+
+// Range filters Libgen's search form doesn't understand; they're only applied to parsed results
+const LOCAL_FILTERS = new Set(['year_min', 'year_max', 'size_max']);
+
+const SIZE_UNITS: Record<string, number> = {
+	b: 1,
+	bytes: 1,
+	kb: 1024,
+	mb: 1024 ** 2,
+	gb: 1024 ** 3
+};
+
+/**
+ * Build the cache key from everything that affects the results:
+ * normalized query, search type, topics and filters
+ */
+function cacheKey(request: LibgenSearchRequest): string {
+	const query = request.query.trim().toLowerCase().replace(/\s+/g, ' ');
+	const topics = [...(request.topics || ['libgen'])].sort();
+	const filters = Object.entries(request.filters || {}).sort(([a], [b]) => a.localeCompare(b));
+
+	return JSON.stringify([query, request.search_type || 'default', topics, filters]);
+}
+
+function getCachedBooks(key: string): BookInfo[] | undefined {
+	const entry = searchCache.get(key);
+	if (!entry) {
+		return undefined;
+	}
+	if (entry.expiresAt <= Date.now()) {
+		searchCache.delete(key);
+		return undefined;
+	}
+	return entry.books.slice();
+}
+
+function setCachedBooks(key: string, books: BookInfo[]): void {
+	// Map iteration order is insertion order, so the first key is the oldest entry
+	if (searchCache.size >= CACHE_MAX_ENTRIES && !searchCache.has(key)) {
+		const oldest = searchCache.keys().next().value;
+		if (oldest !== undefined) {
+			searchCache.delete(oldest);
+		}
+	}
+	searchCache.set(key, { books: books.slice(), expiresAt: Date.now() + CACHE_TTL_MS });
+}
+
+/**
+ * Clear cached search results (used by tests and after mirror changes)
+ */
+export function clearLibgenCache(): void {
+	searchCache.clear();
+}
+
 /**
  * Build search URL with query parameters
  */
@@ -73,6 +154,7 @@ function buildSearchURL(request: LibgenSearchRequest): string {
 	// Add filters if provided
 	if (request.filters) {
 		Object.entries(request.filters).forEach(([key, value]) => {
+			if (LOCAL_FILTERS.has(key)) return;
 			params.set(key, value);
 		});
 	}
@@ -110,7 +192,58 @@ function extractMirrors($: cheerio.Root, cell: any): string[] {
 }
 
 /**
- * Check if a book matches the specified filters
+ * Parse the publication year from a Libgen year field such as "2020" or "2019-2020"
+ */
+export function parseBookYear(year: string): number | undefined {
+	const match = year.match(/\b(\d{4})\b/);
+	return match ? Number(match[1]) : undefined;
+}
+
+/**
+ * Parse a Libgen size field such as "12 MB", "850 kB" or "1.2 Gb" into bytes.
+ * A bare number is taken as bytes; anything else is undefined.
+ */
+export function parseBookSize(size: string): number | undefined {
+	const match = size.trim().match(/^(\d+(?:[.,]\d+)?)\s*([a-z]*)$/i);
+	if (!match) {
+		return undefined;
+	}
+
+	// "1,234" is a thousands separator; "1,5" is a decimal comma
+	const amount = Number(/,\d{3}$/.test(match[1]) ? match[1].replace(',', '') : match[1].replace(',', '.'));
+	const multiplier = SIZE_UNITS[match[2].toLowerCase() || 'b'];
+	if (!Number.isFinite(amount) || multiplier === undefined) {
+		return undefined;
+	}
+
+	return Math.round(amount * multiplier);
+}
+
+/**
+ * Parse a size_max filter value; a bare number is megabytes
+ */
+function parseSizeFilter(value: string): number | undefined {
+	const trimmed = value.trim();
+	return parseBookSize(/^[\d.,]+$/.test(trimmed) ? `${trimmed} MB` : trimmed);
+}
+
+/**
+ * Reject range filters that can't be parsed, so a typo doesn't silently match everything
+ */
+function validateFilters(filters?: Record<string, string>): void {
+	for (const [key, value] of Object.entries(filters || {})) {
+		if ((key === 'year_min' || key === 'year_max') && !/^\d{4}$/.test(value.trim())) {
+			throw new CodedError(`Invalid ${key} filter "${value}": expected a four digit year`, 'invalid_request');
+		}
+		if (key === 'size_max' && parseSizeFilter(value) === undefined) {
+			throw new CodedError(`Invalid size_max filter "${value}": expected megabytes or a size like "500 KB"`, 'invalid_request');
+		}
+	}
+}
+
+/**
+ * Check if a book matches the specified filters. Range filters keep books
+ * whose year or size can't be parsed rather than dropping them.
  */
 function matchesFilters(book: BookInfo, filters?: Record<string, string>): boolean {
 	if (!filters || Object.keys(filters).length === 0) {
@@ -124,6 +257,28 @@ function matchesFilters(book: BookInfo, filters?: Record<string, string>): boole
 					return false;
 				}
 				break;
+			case 'year_min': {
+				const year = parseBookYear(book.year);
+				if (year !== undefined && year < Number(value)) {
+					return false;
+				}
+				break;
+			}
+			case 'year_max': {
+				const year = parseBookYear(book.year);
+				if (year !== undefined && year > Number(value)) {
+					return false;
+				}
+				break;
+			}
+			case 'size_max': {
+				const size = parseBookSize(book.size);
+				const maxSize = parseSizeFilter(value);
+				if (size !== undefined && maxSize !== undefined && size > maxSize) {
+					return false;
+				}
+				break;
+			}
 			case 'extension':
 				if (book.extension.toLowerCase() !== value.toLowerCase()) {
 					return false;
@@ -141,7 +296,104 @@ function matchesFilters(book: BookInfo, filters?: Record<string, string>): boole
 }
 
 /**
- * Extract books from the HTML table
+ * Count books by the same fields matchesFilters understands. Extension and
+ * language are lowercased so "PDF" and "pdf" share a bucket.
+ */
+export function computeLibgenFacets(books: BookInfo[]): LibgenFacets {
+	const facets: LibgenFacets = { extension: {}, language: {}, decade: {} };
+
+	const increment = (counts: Record<string, number>, key: string) => {
+		counts[key] = (counts[key] || 0) + 1;
+	};
+
+	for (const book of books) {
+		increment(facets.extension, book.extension.trim().toLowerCase() || 'unknown');
+		increment(facets.language, book.language.trim().toLowerCase() || 'unknown');
+
+		const year = parseBookYear(book.year);
+		increment(facets.decade, year !== undefined ? `${Math.floor(year / 10) * 10}s` : 'unknown');
+	}
+
+	return facets;
+}
+
+type LibgenColumn = 'title' | 'author' | 'publisher' | 'year' | 'language' | 'pages' | 'size' | 'extension' | 'mirrors';
+
+// Header text patterns, checked in order; the first unassigned match wins
+const COLUMN_PATTERNS: [LibgenColumn, RegExp][] = [
+	['title', /title/],
+	['author', /author/],
+	['publisher', /publisher/],
+	['year', /year/],
+	['language', /lang/],
+	['pages', /pages/],
+	['size', /size/],
+	['extension', /^ext/],
+	['mirrors', /mirror/]
+];
+
+// Without these the remaining fields can't be trusted to line up
+const REQUIRED_COLUMNS: LibgenColumn[] = ['title', 'author', 'extension'];
+
+/**
+ * Map each cell of a row to the column index it starts at, accounting for colspan
+ */
+function cellsByColumn($: cheerio.Root, cells: any): Map<number, any> {
+	const byColumn = new Map<number, any>();
+	let column = 0;
+
+	cells.each((_: number, cell: cheerio.Element) => {
+		byColumn.set(column, $(cell));
+		const span = parseInt($(cell).attr('colspan') || '1', 10);
+		column += Number.isFinite(span) && span > 0 ? span : 1;
+	});
+
+	return byColumn;
+}
+
+/**
+ * Parse the results table header into a column name → index map.
+ * Throws if a required column is missing, since reading cells by position
+ * against an unknown layout silently swaps fields.
+ */
+function parseHeaderColumns($: cheerio.Root, table: any): Map<LibgenColumn, number> {
+	// Use the row with the most <th> cells, so a grouping row above the real header is ignored
+	let headerCells: any = $([]);
+	table.find('tr').each((_: number, row: cheerio.Element) => {
+		const ths = $(row).find('th');
+		if (ths.length > headerCells.length) {
+			headerCells = ths;
+		}
+	});
+
+	const columns = new Map<LibgenColumn, number>();
+	const headerTexts: string[] = [];
+
+	cellsByColumn($, headerCells).forEach((cell, index) => {
+		const text = cell.text().trim().toLowerCase();
+		headerTexts.push(text);
+
+		const match = COLUMN_PATTERNS.find(([name, pattern]) => !columns.has(name) && pattern.test(text));
+		if (match) {
+			columns.set(match[0], index);
+		}
+	});
+
+	const missing = REQUIRED_COLUMNS.filter(name => !columns.has(name));
+	if (missing.length > 0) {
+		// The mirror changed its layout; nothing the caller can fix
+		throw new CodedError(
+			`Libgen results table is missing required columns: ${missing.join(', ')} ` +
+			`(found headers: ${headerTexts.length > 0 ? headerTexts.map(h => `"${h}"`).join(', ') : 'none'})`,
+			'upstream_error'
+		);
+	}
+
+	return columns;
+}
+
+/**
+ * Extract books from the HTML table, reading cells by header name
  */
 function extractBooks($: cheerio.Root, request: LibgenSearchRequest): BookInfo[] {
 	const books: BookInfo[] = [];
@@ -157,6 +409,7 @@ function extractBooks($: cheerio.Root, request: LibgenSearchRequest): BookInfo[]
 		return books;
 	}
 
+	const columns = parseHeaderColumns($, table);
 
 	// Iterate through table rows (skip header rows with th)
 	let rowIndex = 0;
@@ -169,12 +422,23 @@ function extractBooks($: cheerio.Root, request: LibgenSearchRequest): BookInfo[]
 
 		rowIndex++;
 
-		// Extract title and ID from first column
-		const firstCell = cells.eq(0);
+		// Cells spanning several columns (colspan rows) leave the covered columns empty
+		const byColumn = cellsByColumn($, cells);
+		const cellFor = (name: LibgenColumn) => {
+			const index = columns.get(name);
+			return index === undefined ? undefined : byColumn.get(index);
+		};
+		const textFor = (name: LibgenColumn) => cellFor(name)?.text().trim() || '';
 
-		// Libgen.li uses multiple table structures:
+		const titleCell = cellFor('title');
+		if (!titleCell) {
+			skippedRows++;
+			return;
+		}
+
+		// Libgen.li uses multiple title cell structures:
 		// Structure 1: <a href="edition.php" data-toggle="tooltip"> with title in tooltip
-		let editionLink = firstCell.find('a[href*="edition.php"][data-toggle="tooltip"]').first();
+		let editionLink = titleCell.find('a[href*="edition.php"][data-toggle="tooltip"]').first();
 		let tooltipTitle = editionLink.attr('title');
 		let title = '';
 		let id = '';
@@ -195,7 +459,7 @@ function extractBooks($: cheerio.Root, request: LibgenSearchRequest): BookInfo[]
 
 		// Structure 2: <span data-toggle="tooltip"> with title as text (fallback)
 		if (!title) {
-			const span = firstCell.find('span[data-toggle="tooltip"]').first();
+			const span = titleCell.find('span[data-toggle="tooltip"]').first();
 			if (span.length > 0) {
 				const spanClone = span.clone();
 				spanClone.find('font').remove();
@@ -213,7 +477,7 @@ function extractBooks($: cheerio.Root, request: LibgenSearchRequest): BookInfo[]
 
 		// Structure 3: Plain text fallback
 		if (!title) {
-			const cellText = firstCell.text().trim();
+			const cellText = titleCell.text().trim();
 			title = cellText.split('\n')[0].trim();
 		}
 
@@ -221,58 +485,30 @@ function extractBooks($: cheerio.Root, request: LibgenSearchRequest): BookInfo[]
 		if (!title || title.trim() === '') {
 			skippedRows++;
 			if (process.env.DEBUG_LIBGEN) {
-				console.log(`Skipping row ${rowIndex}: empty title. Cells: ${cells.length}, First cell HTML:`, firstCell.html()?.substring(0, 200));
+				console.log(`Skipping row ${rowIndex}: empty title. Cells: ${cells.length}, Title cell HTML:`, titleCell.html()?.substring(0, 200));
 			}
 			return; // Skip this row
 		}
 
-		// Extract book data from cells (structure varies by cell count)
-		let author = '';
-		let publisher = '';
-		let year = '';
-		let language = '';
-		let pages = '';
-		let size = '';
-		let extension = '';
-
-		if (cells.length >= 9) {
-			// 9-cell structure: ID/Title | Author | Publisher | Year | Language | Pages | Size | Extension | Mirrors
-			author = cells.eq(1).text().trim();
-			publisher = cells.eq(2).text().trim();
-			year = cells.eq(3).text().trim();
-			language = cells.eq(4).text().trim();
-			pages = cells.eq(5).text().trim();
-			size = cells.eq(6).text().trim();
-			extension = cells.eq(7).text().trim();
-		} else if (cells.length >= 5) {
-			// 5-cell structure (colspan): Title/Meta | Unknown | Size | Extension | Mirrors
-			size = cells.eq(2).text().trim();
-			extension = cells.eq(3).text().trim();
-		} else {
-			// Minimal structure
-			size = cells.eq(2)?.text().trim() || '';
-			extension = cells.eq(3)?.text().trim() || '';
-		}
-
 		// Extract book data
 		const book: BookInfo = {
 			id: id,
 			title: title,
-			author: author,
-			publisher: publisher,
-			year: year,
-			language: language,
-			pages: pages,
-			size: size,
-			extension: extension,
+			author: textFor('author'),
+			publisher: textFor('publisher'),
+			year: textFor('year'),
+			language: textFor('language'),
+			pages: textFor('pages'),
+			size: textFor('size'),
+			extension: textFor('extension'),
 			md5: id, // Use ID as MD5
 			mirrors: []
 		};
 
-		// Extract mirror links (usually last cell)
-		const mirrorCellIndex = cells.length - 1;
-		if (mirrorCellIndex > 0) {
-			book.mirrors = extractMirrors($, cells.eq(mirrorCellIndex));
+		// Extract mirror links (the mirrors column, or the last cell without one)
+		const mirrorCell = cellFor('mirrors') || (cells.length > 1 ? cells.eq(cells.length - 1) : undefined);
+		if (mirrorCell) {
+			book.mirrors = extractMirrors($, mirrorCell);
 		}
 
 		// Apply filters if specified
@@ -289,11 +525,62 @@ function extractBooks($: cheerio.Root, request: LibgenSearchRequest): BookInfo[]
 }
 
 /**
- * Search for books on Libgen
+ * Parse a Libgen search results page into books
  */
-export async function searchLibgen(request: LibgenSearchRequest): Promise<BookInfo[]> {
+export function parseLibgenResults(html: string, request: LibgenSearchRequest): BookInfo[] {
+	const $ = cheerio.load(html);
+	return extractBooks($, request);
+}
+
+/**
+ * Fetch a results page from a mirror, aborting when the mirror stalls or the
+ * overall search deadline passes
+ */
+async function fetchMirrorPage(url: string, mirrorTimeoutMs: number, deadline?: AbortSignal): Promise<string> {
+	if (deadline?.aborted) {
+		throw new CodedError('Libgen search deadline exceeded', 'timeout');
+	}
+
+	const mirrorSignal = AbortSignal.timeout(mirrorTimeoutMs);
+	const signal = deadline ? AbortSignal.any([mirrorSignal, deadline]) : mirrorSignal;
+
+	try {
+		const response = await fetch(url, {
+			headers: {
+				'User-Agent': USER_AGENT
+			},
+			signal
+		});
+
+		if (!response.ok) {
+			const code = response.status === 404 ? 'not_found' : response.status === 429 ? 'rate_limited' : 'upstream_error';
+			throw new CodedError(`HTTP error: ${response.status} ${response.statusText}`, code);
+		}
+
+		// The signal also covers reading the body, so a mirror that stalls mid-response is abandoned too
+		return await response.text();
+	} catch (error) {
+		if (deadline?.aborted) {
+			throw new CodedError('Libgen search deadline exceeded', 'timeout');
+		}
+		if (mirrorSignal.aborted) {
+			throw new CodedError(`Libgen mirror ${LIBGEN_MIRROR} did not respond within ${mirrorTimeoutMs}ms`, 'timeout');
+		}
+		if (error instanceof CodedError) {
+			throw error;
+		}
+		throw new CodedError(`Libgen mirror request failed: ${error instanceof Error ? error.message : String(error)}`, 'upstream_error');
+	}
+}
+
+/**
+ * Search for books on Libgen. The optional deadline signal bounds the whole
+ * search; callers running several searches share one so they can stop early
+ * and return what they have.
+ */
+export async function searchLibgen(request: LibgenSearchRequest, deadline?: AbortSignal): Promise<BookInfo[]> {
 	if (!request.query) {
-		throw new Error('Query is required');
+		throw new CodedError('Query is required', 'invalid_request');
 	}
 
 	// Set defaults
@@ -303,6 +590,16 @@ export async function searchLibgen(request: LibgenSearchRequest): Promise<BookIn
 	if (!request.topics || request.topics.length === 0) {
 		request.topics = ['libgen'];
 	}
+	validateFilters(request.filters);
+
+	const key = cacheKey(request);
+	if (!request.no_cache) {
+		const cached = getCachedBooks(key);
+		if (cached) {
+			console.log(`Returning ${cached.length} cached books for query: ${request.query}`);
+			return cached;
+		}
+	}
 
 	// Build search URL
 	const searchURL = buildSearchURL(request);
@@ -310,18 +607,7 @@ export async function searchLibgen(request: LibgenSearchRequest): Promise<BookIn
 
 	try {
 		// Perform HTTP request
-		const response = await fetch(searchURL, {
-			headers: {
-				'User-Agent': USER_AGENT
-			},
-			signal: AbortSignal.timeout(30000) // 30 second timeout
-		});
-
-		if (!response.ok) {
-			throw new Error(`HTTP error: ${response.status} ${response.statusText}`);
-		}
-
-		const html = await response.text();
+		const html = await fetchMirrorPage(searchURL, request.mirror_timeout_ms || MIRROR_TIMEOUT_MS, deadline);
 		console.log(`Received HTML response, length: ${html.length} bytes`);
 
 		// Save HTML for debugging (only first 5000 chars)
@@ -329,14 +615,15 @@ export async function searchLibgen(request: LibgenSearchRequest): Promise<BookIn
 			console.log('HTML snippet:', html.substring(0, 5000));
 		}
 
-		// Parse HTML with cheerio
-		const $ = cheerio.load(html);
-		console.log(`HTML parsed with cheerio`);
-
-		// Extract books from table
-		const books = extractBooks($, request);
+		// Parse HTML and extract books from the results table
+		const books = parseLibgenResults(html, request);
 
 		console.log(`Found ${books.length} books for query: ${request.query}`);
+		// An empty page may be a mirror error or captcha rather than a real
+		// miss, so only successful results are cached
+		if (books.length > 0) {
+			setCachedBooks(key, books);
+		}
 		return books;
 	} catch (error) {
 		console.error('Libgen search failed:', error);
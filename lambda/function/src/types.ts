@@ -3,6 +3,9 @@
  * Ported from Supabase Edge Function
  */
 
+import type { ContentImportSource, SubtitleTrack } from './go-client.js';
+import type { TranscriptSegment } from './python-client.js';
+
 export interface ContentQueueJob {
 	action: 'seo-extract' | 'llm-generate' | 'screenshot-process';
 	payload: any;
@@ -13,7 +16,7 @@ export interface ContentQueueJob {
 }
 
 export interface ContentRequest {
-	action: 'seo-extract' | 'llm-generate' | 'screenshot-queue' | 'queue-process' | 'markdown-extract' | 'chat-message' | 'claude-code-execute' | 'claude-code' | 'youtube-playlist-extract' | 'youtube-subtitle-extract' | 'tmdb-search' | 'libgen-search' | 'get-job' | 'list-jobs' | 'cancel-job' | 'tsx-transpile' | 'transcribe-audio' | 'teller-accounts' | 'teller-balances' | 'teller-transactions' | 'send-notification' | 'register-device' | 'unregister-device' | 'blocknote-export' | 'stripe-connect-onboard' | 'stripe-connect-status' | 'stripe-connect-dashboard' | 'stripe-create-transfer' | 'stripe-list-transfers' | 'stripe-initiate-payout' | 'stripe-list-payouts' | 'stripe-search-users' | 'stripe-webhook' | 'auth-generate-token' | 'auth-redeem-token' | 'auth-revoke-token' | 'auth-validate-session' | 'auth-list-tokens';
+	action: 'seo-extract' | 'llm-generate' | 'screenshot-queue' | 'queue-process' | 'markdown-extract' | 'chat-message' | 'claude-code-execute' | 'claude-code' | 'youtube-playlist-extract' | 'youtube-subtitle-extract' | 'tmdb-search' | 'libgen-search' | 'content-import' | 'content-tree' | 'content-search' | 'content-move' | 'get-job' | 'list-jobs' | 'cancel-job' | 'tsx-transpile' | 'transcribe-audio' | 'teller-accounts' | 'teller-balances' | 'teller-transactions' | 'send-notification' | 'register-device' | 'unregister-device' | 'blocknote-export' | 'stripe-connect-onboard' | 'stripe-connect-status' | 'stripe-connect-dashboard' | 'stripe-create-transfer' | 'stripe-list-transfers' | 'stripe-initiate-payout' | 'stripe-list-payouts' | 'stripe-search-users' | 'stripe-webhook' | 'auth-generate-token' | 'auth-redeem-token' | 'auth-revoke-token' | 'auth-validate-session' | 'auth-list-tokens';
 	payload: any;
 	sync?: boolean; // When true, execute immediately and return results. When false/omitted, queue job (default)
 }
@@ -138,6 +141,7 @@ export interface ClaudeCodeJobResponse {
 // YouTube Playlist Types
 export interface YouTubePlaylistPayload {
 	selectedContent: ContentItem[];
+	withTranscripts?: boolean; // Also store each video's captions as a child transcript (batched subtitle fetches, time-bounded)
 }
 
 // YouTube Subtitle Types
@@ -246,6 +250,53 @@ export interface LibgenSearchPayload {
 	filters?: Record<string, string>;
 	maxResults?: number; // Max results per content item
 	autoCreate?: boolean; // If false, return book metadata without creating Content items (default: true)
+	noCache?: boolean; // Force a fresh scrape instead of using cached results
+	mirrorTimeoutMs?: number; // Per-request Libgen mirror timeout
+	deadlineMs?: number; // Overall deadline for all searches; later items fail once it passes
+}
+
+// Content Import Types
+// The caller is taken from the request's bearer token and owns the imported content
+export interface ContentImportPayload {
+	group_id?: string; // Required unless create_group is set
+	create_group?: boolean; // Import into a new group, e.g. one named after an RSS feed
+	parent_content_id?: string; // Import under existing content instead of a new list
+	title?: string; // Data for the created list; defaults to the feed title for rss
+	tags?: string[]; // Applied to the parent and every imported item
+	source: ContentImportSource | YouTubePlaylistImportSource;
+}
+
+// Playlists are fetched and mapped here, then imported as items
+export interface YouTubePlaylistImportSource {
+	type: 'youtube_playlist';
+	playlist_url: string;
+	max_videos?: number;
+}
+
+// Content Tree Types
+// The caller is taken from the request's bearer token and must be a group member
+export interface ContentTreePayload {
+	group_id: string;
+	root_id?: string; // Subtree root; otherwise the group's top-level items
+	max_depth?: number;
+	max_nodes?: number;
+}
+
+// Content Search Types
+// The caller is taken from the request's bearer token and must be a group member
+export interface ContentSearchPayload {
+	group_id: string;
+	query: string; // websearch syntax: "exact phrase", -exclude, OR
+	limit?: number; // Defaults to 20, capped at 100
+	offset?: number;
+	include_metadata?: boolean;
+}
+
+// Content Move Types
+// The caller is taken from the request's bearer token and must be a member of the content's group
+export interface ContentMovePayload {
+	content_id: string;
+	new_parent_id?: string | null; // Null or omitted moves the content to the top level
 }
 
 // Job Queue Types
@@ -402,10 +453,21 @@ export interface DeepgramResponse {
 	results: DeepgramResults;
 }
 
+// A transcript segment with Deepgram's word timings, in milliseconds like the segment itself
+export interface TimedTranscriptSegment extends TranscriptSegment {
+	speaker?: number;
+	words: Array<{
+		word: string;
+		offset: number;
+		duration: number;
+	}>;
+}
+
 export interface TranscribeAudioResult {
 	content_id: string;
 	success: boolean;
 	transcript_content_id?: string;
+	track?: SubtitleTrack; // The transcript as a caption track, like youtube.subtitles returns
 	error?: string;
 }
 
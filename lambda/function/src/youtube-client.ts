@@ -1,25 +1,49 @@
-import { executeGo } from './go-executor.js';
+import { executeGo, GoMethodError } from './go-executor.js';
 import type { PlaylistRequest, PlaylistResponse, VideoInfo } from './go-client.js';
 import { isPlaylistResponse } from './go-client.js';
 
+// Matches the Go method's own default deadline and upper bound
+const DEFAULT_PLAYLIST_TIMEOUT_SECONDS = 25;
+const MAX_PLAYLIST_TIMEOUT_SECONDS = 240;
+// Time for the Go process to return what it has once its deadline passes
+const PLAYLIST_EXIT_HEADROOM_MS = 5000;
+
 /**
- * Get videos from a YouTube playlist URL
+ * Get videos from a YouTube playlist URL, along with the videos whose full
+ * details couldn't be fetched (those carry playlist entry data only)
  */
-export async function getPlaylistVideos(url: string): Promise<VideoInfo[]> {
-	const request: PlaylistRequest = { url };
+export async function getPlaylist(
+	url: string,
+	maxVideos?: number,
+	timeoutSeconds?: number
+): Promise<PlaylistResponse> {
+	const request: PlaylistRequest = { url, max_videos: maxVideos, timeout_seconds: timeoutSeconds };
 
-	const response = await executeGo({
-		method: 'youtube.playlist',
-		params: request
-	});
+	// Kill the process only after Go's deadline, so fallback data isn't lost
+	const deadlineSeconds = Math.min(timeoutSeconds || DEFAULT_PLAYLIST_TIMEOUT_SECONDS, MAX_PLAYLIST_TIMEOUT_SECONDS);
+	const response = await executeGo(
+		{
+			method: 'youtube.playlist',
+			params: request
+		},
+		{ timeout: deadlineSeconds * 1000 + PLAYLIST_EXIT_HEADROOM_MS }
+	);
 
 	if (!response.success) {
-		throw new Error(`YouTube playlist fetch failed: ${response.error}`);
+		throw new GoMethodError(`YouTube playlist fetch failed: ${response.error}`, response.error_code);
 	}
 
 	if (!isPlaylistResponse(response.result)) {
 		throw new Error('Invalid playlist response format');
 	}
 
-	return response.result.videos;
+	return response.result;
+}
+
+/**
+ * Get videos from a YouTube playlist URL
+ */
+export async function getPlaylistVideos(url: string): Promise<VideoInfo[]> {
+	const playlist = await getPlaylist(url);
+	return playlist.videos;
 }
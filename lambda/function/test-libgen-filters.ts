@@ -0,0 +1,83 @@
+/**
+ * Offline test of Libgen year and size range filters
+ * Run with: npx tsx test-libgen-filters.ts
+ */
+import { parseBookSize, parseLibgenResults } from './src/libgen-search.js';
+
+function row(title: string, year: string, size: string): string {
+	return `<tr><td>${title}</td><td>Author</td><td>${year}</td><td>${size}</td><td>pdf</td><td><a href="/ads.php?md5=x">[1]</a></td></tr>`;
+}
+
+const RESULTS = `
+<table id="tablelibgen">
+	<tr><th>Title</th><th>Author(s)</th><th>Year</th><th>Size</th><th>Ext.</th><th>Mirrors</th></tr>
+	${row('Old Small', '1995', '850 kB')}
+	${row('New Large', '2021', '1.2 GB')}
+	${row('Mid Medium', '2008', '12 MB')}
+	${row('Unknown Year', '', '3 MB')}
+	${row('Unknown Size', '2015', 'n/a')}
+</table>`;
+
+interface FilterCase {
+	filters: Record<string, string>;
+	expectedTitles: string[];
+	desc: string;
+}
+
+const filterCases: FilterCase[] = [
+	{
+		filters: { year_min: '2000', year_max: '2010' },
+		expectedTitles: ['Mid Medium', 'Unknown Year'],
+		desc: 'year range keeps books without a year'
+	},
+	{
+		filters: { size_max: '20' },
+		expectedTitles: ['Old Small', 'Mid Medium', 'Unknown Year', 'Unknown Size'],
+		desc: 'size_max in megabytes keeps books without a size'
+	},
+	{
+		filters: { size_max: '1 MB' },
+		expectedTitles: ['Old Small', 'Unknown Size'],
+		desc: 'size_max with a unit suffix'
+	}
+];
+
+const sizeCases: Array<[string, number | undefined]> = [
+	['12 MB', 12 * 1024 ** 2],
+	['850 kB', 850 * 1024],
+	['1.5 Gb', 1.5 * 1024 ** 3],
+	['1,5 MB', 1.5 * 1024 ** 2],
+	['4096', 4096],
+	['n/a', undefined],
+	['5 TB', undefined]
+];
+
+function run(): number {
+	let failures = 0;
+
+	for (const [input, expected] of sizeCases) {
+		const actual = parseBookSize(input);
+		if (actual !== expected) {
+			console.log(`❌ parseBookSize(${JSON.stringify(input)}): expected ${expected}, got ${actual}`);
+			failures++;
+			continue;
+		}
+		console.log(`✓ Parsed size: ${JSON.stringify(input)}`);
+	}
+
+	for (const tc of filterCases) {
+		const titles = parseLibgenResults(RESULTS, { query: 'books', filters: tc.filters }).map(book => book.title);
+		if (JSON.stringify(titles) !== JSON.stringify(tc.expectedTitles)) {
+			console.log(`❌ ${tc.desc}: expected ${JSON.stringify(tc.expectedTitles)}, got ${JSON.stringify(titles)}`);
+			failures++;
+			continue;
+		}
+		console.log(`✓ Validated: ${tc.desc}`);
+	}
+
+	return failures;
+}
+
+const failures = run();
+console.log(failures === 0 ? '\n✅ All filter cases passed' : `\n❌ ${failures} filter case(s) failed`);
+process.exit(failures === 0 ? 0 : 1);
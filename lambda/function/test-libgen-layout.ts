@@ -0,0 +1,138 @@
+/**
+ * Offline test of Libgen results table parsing against layout fixtures
+ * Run with: npx tsx test-libgen-layout.ts
+ */
+import { parseLibgenResults, type BookInfo } from './src/libgen-search.js';
+
+const MIRRORS_CELL = '<td><a href="/ads.php?md5=abc123">[1]</a><a href="https://library.lol/main/abc123">[2]</a></td>';
+
+// Standard libgen.li layout
+const STANDARD_LAYOUT = `
+<table id="tablelibgen">
+	<thead><tr>
+		<th>ID / Title</th><th>Author(s)</th><th>Publisher</th><th>Year</th><th>Language</th>
+		<th>Pages</th><th>Size</th><th>Ext.</th><th>Mirrors</th>
+	</tr></thead>
+	<tbody>
+		<tr>
+			<td><a href="edition.php?id=1" data-toggle="tooltip" title="Add/Edit: 2020; ID: 1001<br>Designing Interfaces">x</a></td>
+			<td>Jenifer Tidwell</td><td>O'Reilly</td><td>2020</td><td>English</td>
+			<td>599</td><td>12 MB</td><td>pdf</td>${MIRRORS_CELL}
+		</tr>
+	</tbody>
+</table>`;
+
+// Same data with author/title swapped and extension moved ahead of size
+const REORDERED_LAYOUT = `
+<table id="tablelibgen">
+	<tr>
+		<th>Author(s)</th><th>Title</th><th>Ext.</th><th>Size</th><th>Year</th><th>Language</th>
+		<th>Publisher</th><th>Mirrors</th>
+	</tr>
+	<tr>
+		<td>Jenifer Tidwell</td>
+		<td><span data-toggle="tooltip" title="File ID: 1001">Designing Interfaces<font>ed. 3</font></span></td>
+		<td>pdf</td><td>12 MB</td><td>2020</td><td>English</td><td>O'Reilly</td>${MIRRORS_CELL}
+	</tr>
+</table>`;
+
+// Title cell spanning the author..pages columns, as on some mirrors
+const COLSPAN_LAYOUT = `
+<table id="tablelibgen">
+	<thead><tr>
+		<th>Title</th><th>Author(s)</th><th>Publisher</th><th>Year</th><th>Language</th>
+		<th>Pages</th><th>Size</th><th>Ext.</th><th>Mirrors</th>
+	</tr></thead>
+	<tr>
+		<td colspan="6">Designing Interfaces</td><td>12 MB</td><td>pdf</td>${MIRRORS_CELL}
+	</tr>
+</table>`;
+
+const MISSING_HEADERS_LAYOUT = `
+<table id="tablelibgen">
+	<tr><th>Name</th><th>Size</th><th>Mirrors</th></tr>
+	<tr><td>Designing Interfaces</td><td>Jenifer Tidwell</td><td>12 MB</td><td>pdf</td>${MIRRORS_CELL}</tr>
+</table>`;
+
+interface LayoutCase {
+	html: string;
+	expected?: Partial<BookInfo>;
+	expectedError?: string;
+	desc: string;
+}
+
+const testCases: LayoutCase[] = [
+	{
+		html: STANDARD_LAYOUT,
+		expected: { id: '1001', title: 'Designing Interfaces', author: 'Jenifer Tidwell', publisher: "O'Reilly", year: '2020', language: 'English', pages: '599', size: '12 MB', extension: 'pdf' },
+		desc: 'standard layout'
+	},
+	{
+		html: REORDERED_LAYOUT,
+		expected: { id: '1001', title: 'Designing Interfaces', author: 'Jenifer Tidwell', publisher: "O'Reilly", year: '2020', language: 'English', pages: '', size: '12 MB', extension: 'pdf' },
+		desc: 'reordered columns without thead'
+	},
+	{
+		html: COLSPAN_LAYOUT,
+		expected: { title: 'Designing Interfaces', author: '', year: '', size: '12 MB', extension: 'pdf' },
+		desc: 'colspan title cell'
+	},
+	{
+		html: MISSING_HEADERS_LAYOUT,
+		expectedError: 'missing required columns: title, author, extension',
+		desc: 'missing required headers'
+	}
+];
+
+function run(): number {
+	let failures = 0;
+
+	for (const tc of testCases) {
+		try {
+			const books = parseLibgenResults(tc.html, { query: 'designing interfaces' });
+
+			if (tc.expectedError) {
+				console.log(`❌ ${tc.desc}: expected error containing "${tc.expectedError}", got ${books.length} books`);
+				failures++;
+				continue;
+			}
+
+			if (books.length !== 1) {
+				console.log(`❌ ${tc.desc}: expected 1 book, got ${books.length}`);
+				failures++;
+				continue;
+			}
+
+			const book = books[0];
+			const mismatches = Object.entries(tc.expected || {})
+				.filter(([field, value]) => book[field as keyof BookInfo] !== value)
+				.map(([field, value]) => `${field}: expected ${JSON.stringify(value)}, got ${JSON.stringify(book[field as keyof BookInfo])}`);
+
+			if (book.mirrors[0] !== 'https://libgen.li/ads.php?md5=abc123') {
+				mismatches.push(`mirrors[0]: got ${JSON.stringify(book.mirrors[0])}`);
+			}
+
+			if (mismatches.length > 0) {
+				console.log(`❌ ${tc.desc}:\n   ${mismatches.join('\n   ')}`);
+				failures++;
+				continue;
+			}
+
+			console.log(`✓ Validated: ${tc.desc}`);
+		} catch (error) {
+			const message = error instanceof Error ? error.message : String(error);
+			if (tc.expectedError && message.includes(tc.expectedError)) {
+				console.log(`✓ Correctly returned error: ${message}`);
+				continue;
+			}
+			console.log(`❌ ${tc.desc}: unexpected error: ${message}`);
+			failures++;
+		}
+	}
+
+	return failures;
+}
+
+const failures = run();
+console.log(failures === 0 ? '\n✅ All layout fixtures passed' : `\n❌ ${failures} layout fixture(s) failed`);
+process.exit(failures === 0 ? 0 : 1);
@@ -17,6 +17,10 @@ const mapkitKeyId = config.require('mapkit_key_id');
 const mapkitPrivateKey = config.requireSecret('mapkit_private_key');
 const tellerClientCert = config.requireSecret('teller_client_cert');
 const tellerClientKey = config.requireSecret('teller_client_key');
+// Direct Postgres connection for the Go handler's content.* methods (optional)
+const databaseUrl = config.getSecret('database_url') || pulumi.output('');
+// Proxy for YouTube requests from the Go handler, which YouTube often blocks from AWS IPs (optional)
+const youtubeProxyUrl = config.getSecret('youtube_proxy_url') || pulumi.output('');
 
 // APNs Push Notification Configuration
 const apnsTeamId = config.get('apns_team_id') || '';
@@ -223,6 +227,8 @@ const lambdaFunction = new aws.lambda.Function('claude-code-lambda', {
 			CLOUDFLARE_ACCOUNT_ID: cloudflareAccountId,
 			TMDB_API_KEY: tmdbApiKey,
 			DEEPGRAM_API_KEY: deepgramApiKey,
+			DATABASE_URL: databaseUrl,
+			YOUTUBE_PROXY_URL: youtubeProxyUrl,
 			MAPKIT_TEAM_ID: mapkitTeamId,
 			MAPKIT_KEY_ID: mapkitKeyId,
 			MAPKIT_PRIVATE_KEY: mapkitPrivateKey,
@@ -249,7 +255,7 @@ const api = new aws.apigatewayv2.Api('claude-code-api', {
 	corsConfiguration: {
 		allowOrigins: ['*'],
 		allowMethods: ['GET', 'POST', 'OPTIONS'],
-		allowHeaders: ['Content-Type'],
+		allowHeaders: ['Content-Type', 'Authorization'],
 		maxAge: 86400
 	},
 	tags: {